@@ -33,6 +33,8 @@ ddev composer outdated --minor-only`,
 			}
 		}
 
+		app.WarnComposerPHPDrift()
+
 		stdout, _, err := app.Exec(&ddevapp.ExecOpts{
 			Service: "web",
 			Dir:     "/var/www/html",
@@ -13,13 +13,15 @@ var (
 	instrumentationOptIn bool
 	// omitContainers allows user to set value of omit_containers
 	omitContainers string
+	// routerPortFallback allows user to set value of router_port_fallback
+	routerPortFallback bool
 )
 
 // configGlobalCommand is the the `ddev config global` command
 var configGlobalCommand *cobra.Command = &cobra.Command{
 	Use:     "global [flags]",
 	Short:   "Change global configuration",
-	Example: "ddev config global --instrumentation-opt-in=false\nddev config global --omit-containers=dba,ddev-ssh-agent",
+	Example: "ddev config global --instrumentation-opt-in=false\nddev config global --omit-containers=dba,ddev-ssh-agent\nddev config global --omit-containers=ddev-router\nddev config global --router-port-fallback=true",
 	Run:     handleGlobalConfig,
 }
 
@@ -43,6 +45,9 @@ func handleGlobalConfig(cmd *cobra.Command, args []string) {
 			globalconfig.DdevGlobalConfig.OmitContainers = strings.Split(omitContainers, ",")
 		}
 	}
+	if cmd.Flag("router-port-fallback").Changed {
+		globalconfig.DdevGlobalConfig.RouterPortFallback = routerPortFallback
+	}
 	err = globalconfig.ValidateGlobalConfig()
 	if err != nil {
 		util.Failed("Invalid configuration in %s: %v", globalconfig.GetGlobalConfigPath(), err)
@@ -55,11 +60,13 @@ func handleGlobalConfig(cmd *cobra.Command, args []string) {
 	util.Success("Global configuration:")
 	output.UserOut.Printf("instrumentation-opt-in=%v", globalconfig.DdevGlobalConfig.InstrumentationOptIn)
 	output.UserOut.Printf("omit-containers=[%s]", strings.Join(globalconfig.DdevGlobalConfig.OmitContainers, ","))
+	output.UserOut.Printf("router-port-fallback=%v", globalconfig.DdevGlobalConfig.RouterPortFallback)
 }
 
 func init() {
-	configGlobalCommand.Flags().StringVarP(&omitContainers, "omit-containers", "", "", "omit-containers=dba,ddev-ssh-agent")
+	configGlobalCommand.Flags().StringVarP(&omitContainers, "omit-containers", "", "", "omit-containers=dba,ddev-ssh-agent,ddev-router")
 	configGlobalCommand.Flags().BoolVarP(&instrumentationOptIn, "instrumentation-opt-in", "", false, "instrmentation-opt-in=true")
+	configGlobalCommand.Flags().BoolVarP(&routerPortFallback, "router-port-fallback", "", false, "router-port-fallback=true picks free router ports when the configured ones are in use")
 
 	ConfigCommand.AddCommand(configGlobalCommand)
 }
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/output"
+	"github.com/drud/ddev/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// configValidateCommand implements the ddev config validate command
+var configValidateCommand *cobra.Command = &cobra.Command{
+	Use:     "validate [project]",
+	Short:   "Check the project's config.yaml and config.*.yaml files for unknown keys and invalid values",
+	Example: "ddev config validate\nddev config validate <projectname>\nddev config validate -j",
+	Args:    cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		projectName := ""
+		if len(args) == 1 {
+			projectName = args[0]
+		}
+
+		appRoot, err := ddevapp.GetActiveAppRoot(projectName)
+		if err != nil {
+			util.Failed("Failed to find the project: %v", err)
+		}
+		// The config may not load at all; what ValidateConfigSchema reports
+		// explains why, so NewApp's own error isn't needed.
+		app, _ := ddevapp.NewApp(appRoot, true, "")
+		if app == nil {
+			util.Failed("Failed to read the configuration of the project in %s", appRoot)
+		}
+
+		problems, err := app.ValidateConfigSchema(true)
+		if err != nil {
+			util.Failed("Failed to validate the configuration of project %s: %v", app.Name, err)
+		}
+		if len(problems) == 0 {
+			output.UserOut.WithField("raw", problems).Printf("The configuration of project %s is valid", app.Name)
+			return
+		}
+
+		var lines []string
+		for _, p := range problems {
+			lines = append(lines, p.Error())
+		}
+		output.UserOut.WithField("raw", problems).Print(strings.Join(lines, "\n"))
+		util.Failed("Found %d problem(s) in the configuration of project %s", len(problems), app.Name)
+	},
+}
+
+func init() {
+	ConfigCommand.AddCommand(configValidateCommand)
+}
@@ -42,6 +42,15 @@ var (
 	// xdebugEnabledArg allows a user to enable XDebug from a command flag.
 	xdebugEnabledArg bool
 
+	// profilerArg allows a user to set the project's profiler
+	profilerArg string
+
+	// nodejsVersionArg allows a user to set the Node.js version of the web container
+	nodejsVersionArg string
+
+	// composerVersionArg allows a user to set the composer version of the web container
+	composerVersionArg string
+
 	// additionalHostnamesArg allows a user to provide a comma-delimited list of hostnames from a command flag.
 	additionalHostnamesArg string
 
@@ -51,6 +60,9 @@ var (
 	// showConfigLocation, if set, causes the command to show the config location.
 	showConfigLocation bool
 
+	// detectArg, if set, causes the command to report the project type and docroot detection.
+	detectArg bool
+
 	// uploadDirArg allows a user to set the project's upload directory, the destination directory for import-files.
 	uploadDirArg string
 
@@ -128,6 +140,9 @@ var (
 	webimageExtraPackages string
 	dbimageExtraPackages  string
 
+	// phpExtensionsArg is a comma-delimited list of PECL extensions to build into the web container
+	phpExtensionsArg string
+
 	// projectTLDArg specifies a project top-level-domain; defaults to ddevapp.DdevDefaultTLD
 	projectTLDArg string
 
@@ -161,6 +176,12 @@ func handleConfigRun(cmd *cobra.Command, args []string) {
 		util.Failed("Please do not use `ddev config` in your home directory")
 	}
 
+	if detectArg {
+		report := app.DetectionReport()
+		output.UserOut.WithField("raw", report).Print(renderDetectionReport(report))
+		return
+	}
+
 	if cmd.Flags().NFlag() == 0 {
 		err = app.PromptForConfig()
 		if err != nil {
@@ -220,11 +241,15 @@ func init() {
 	ConfigCommand.Flags().StringVar(&httpPortArg, "http-port", "", "The router HTTP port for this project")
 	ConfigCommand.Flags().StringVar(&httpsPortArg, "https-port", "", "The router HTTPS port for this project")
 	ConfigCommand.Flags().BoolVar(&xdebugEnabledArg, "xdebug-enabled", false, "Whether or not XDebug is enabled in the web container")
+	ConfigCommand.Flags().StringVar(&profilerArg, "profiler", "", fmt.Sprintf("The profiler to set up for the project (%s), empty to disable", strings.Join(ddevapp.GetValidProfilers(), ", ")))
+	ConfigCommand.Flags().StringVar(&composerVersionArg, "composer-version", "", `The composer version of the web container: "1", "2" or a version like "1.10.1", empty for the web image's own`)
+	ConfigCommand.Flags().StringVar(&nodejsVersionArg, "nodejs-version", "", fmt.Sprintf("The Node.js major version of the web container (%s), empty for the web image's own", strings.Join(ddevapp.GetValidNodeJSVersions(), ", ")))
 	ConfigCommand.Flags().StringVar(&additionalHostnamesArg, "additional-hostnames", "", "A comma-delimited list of hostnames for the project")
 	ConfigCommand.Flags().StringVar(&additionalFQDNsArg, "additional-fqdns", "", "A comma-delimited list of FQDNs for the project")
 	ConfigCommand.Flags().StringVar(&omitContainersArg, "omit-containers", "", "A comma-delimited list of container types that should not be started when the project is started")
 	ConfigCommand.Flags().BoolVar(&createDocroot, "create-docroot", false, "Prompts ddev to create the docroot if it doesn't exist")
 	ConfigCommand.Flags().BoolVar(&showConfigLocation, "show-config-location", false, "Output the location of the config.yaml file if it exists, or error that it doesn't exist.")
+	ConfigCommand.Flags().BoolVar(&detectArg, "detect", false, "Report the project types and docroots detected in the project, without changing the configuration")
 	ConfigCommand.Flags().StringVar(&uploadDirArg, "upload-dir", "", "Sets the project's upload directory, the destination directory of the import-files command.")
 	ConfigCommand.Flags().StringVar(&webserverTypeArg, "webserver-type", "", "Sets the project's desired webserver type: nginx-fpm, apache-fpm, or apache-cgi")
 	ConfigCommand.Flags().StringVar(&webImageArg, "web-image", "", "Sets the web container image")
@@ -272,6 +297,8 @@ func init() {
 
 	ConfigCommand.Flags().StringVar(&webimageExtraPackages, "webimage-extra-packages", "", "A comma-delimited list of Debian packages that should be added to web container when the project is started")
 
+	ConfigCommand.Flags().StringVar(&phpExtensionsArg, "php-extensions", "", "A comma-delimited list of PECL extensions, like redis, imagick@3.4.4 or pecl_http:http (package:module), that should be built into the web container when the project is started")
+
 	ConfigCommand.Flags().StringVar(&dbimageExtraPackages, "dbimage-extra-packages", "", "A comma-delimited list of Debian packages that should be added to db container when the project is started")
 
 	ConfigCommand.Flags().StringVar(&projectTLDArg, "project-tld", ddevapp.DdevDefaultTLD, "set the top-level domain to be used for projects, defaults to "+ddevapp.DdevDefaultTLD)
@@ -297,9 +324,34 @@ func getConfigApp(providerName string) (*ddevapp.DdevApp, error) {
 	if err != nil {
 		return nil, fmt.Errorf("could not create new config: %v", err)
 	}
+	app.WarnConfigSchemaProblems(false)
 	return app, nil
 }
 
+// renderDetectionReport() returns the detection report as friendly text.
+func renderDetectionReport(report *ddevapp.DetectionReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Detected project type: %s\n", report.AppType)
+	fmt.Fprintf(&b, "Detected docroot: %s\n", ddevapp.DocrootDisplayName(report.Docroot))
+
+	if len(report.Matches) > 0 {
+		b.WriteString("\nMatching project types:\n")
+		for _, m := range report.Matches {
+			fmt.Fprintf(&b, "  %s (docroot %s): %s\n", m.AppType, ddevapp.DocrootDisplayName(m.Docroot), strings.Join(m.Evidence, ", "))
+		}
+	}
+	if len(report.Docroots) > 0 {
+		b.WriteString("\nDocroot candidates:\n")
+		for _, d := range report.Docroots {
+			fmt.Fprintf(&b, "  %s: %s\n", ddevapp.DocrootDisplayName(d.Docroot), strings.Join(d.Evidence, ", "))
+		}
+	}
+	for _, w := range report.Warnings {
+		fmt.Fprintf(&b, "\nWarning: %s", w)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
 // handleMainConfigArgs() validates and processes the main config args (docroot, etc.)
 func handleMainConfigArgs(cmd *cobra.Command, args []string, app *ddevapp.DdevApp) error {
 	var err error
@@ -365,8 +417,8 @@ func handleMainConfigArgs(cmd *cobra.Command, args []string, app *ddevapp.DdevAp
 		app.Docroot = ddevapp.DiscoverDefaultDocroot(app)
 	}
 
-	if projectTypeArg != "" && !ddevapp.IsValidAppType(projectTypeArg) {
-		validAppTypes := strings.Join(ddevapp.GetValidAppTypes(), ", ")
+	if projectTypeArg != "" && !app.IsValidProjectType(projectTypeArg) {
+		validAppTypes := strings.Join(app.GetValidProjectTypes(), ", ")
 		util.Failed("apptype must be one of %s", validAppTypes)
 	}
 
@@ -435,6 +487,18 @@ func handleMainConfigArgs(cmd *cobra.Command, args []string, app *ddevapp.DdevAp
 		app.XdebugEnabled = xdebugEnabledArg
 	}
 
+	if cmd.Flag("profiler").Changed {
+		app.Profiler = profilerArg
+	}
+
+	if cmd.Flag("nodejs-version").Changed {
+		app.NodeJSVersion = nodejsVersionArg
+	}
+
+	if cmd.Flag("composer-version").Changed {
+		app.ComposerVersion = composerVersionArg
+	}
+
 	if cmd.Flag("phpmyadmin-port").Changed {
 		app.PHPMyAdminPort = phpMyAdminPortArg
 	}
@@ -462,6 +526,14 @@ func handleMainConfigArgs(cmd *cobra.Command, args []string, app *ddevapp.DdevAp
 		}
 	}
 
+	if cmd.Flag("php-extensions").Changed {
+		if phpExtensionsArg == "" {
+			app.PHPExtensions = nil
+		} else {
+			app.PHPExtensions = strings.Split(phpExtensionsArg, ",")
+		}
+	}
+
 	if cmd.Flag("dbimage-extra-packages").Changed {
 		if dbimageExtraPackages == "" {
 			app.DBImageExtraPackages = nil
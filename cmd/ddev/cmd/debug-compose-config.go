@@ -4,7 +4,6 @@ import (
 	"strings"
 
 	"github.com/drud/ddev/pkg/ddevapp"
-	"github.com/drud/ddev/pkg/dockerutil"
 	"github.com/drud/ddev/pkg/output"
 	"github.com/drud/ddev/pkg/util"
 	"github.com/spf13/cobra"
@@ -34,13 +33,7 @@ var DebugComposeConfigCmd = &cobra.Command{
 			util.Failed("Failed to get compose-config: %v", err)
 		}
 
-		app.DockerEnv()
-		files, err := app.ComposeFiles()
-		if err != nil {
-			util.Failed("Failed to get compose-config: %v", err)
-		}
-
-		out, _, err := dockerutil.ComposeCmd(files, "config")
+		out, err := app.ComposeConfig()
 		if err != nil {
 			util.Failed("Failed to get compose-config: %v", err)
 		}
@@ -5,15 +5,19 @@ import (
 	"github.com/drud/ddev/pkg/output"
 	"github.com/drud/ddev/pkg/util"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
 	"reflect"
 	"strings"
 )
 
+// configYamlEffective is the --effective flag of ddev debug configyaml
+var configYamlEffective bool
+
 // DebugConfigYamlCmd implements the ddev debug configyaml command
 var DebugConfigYamlCmd = &cobra.Command{
 	Use:     "configyaml [project]",
 	Short:   "Prints the project config.*.yaml usage",
-	Example: "ddev debug configyaml, ddev debug configyaml <projectname>",
+	Example: "ddev debug configyaml, ddev debug configyaml <projectname>, ddev debug configyaml --effective",
 	Run: func(cmd *cobra.Command, args []string) {
 		projectName := ""
 
@@ -29,6 +33,16 @@ var DebugConfigYamlCmd = &cobra.Command{
 		if err != nil {
 			util.Failed("Failed to get active project: %v", err)
 		}
+
+		if configYamlEffective {
+			effective, err := app.EffectiveConfig(true)
+			if err != nil {
+				util.Failed("Failed to read the config of project %s: %v", app.Name, err)
+			}
+			output.UserOut.WithField("raw", effective).Print(renderEffectiveConfig(effective))
+			return
+		}
+
 		configFiles, err := app.ReadConfig(true)
 		if err != nil {
 			util.Error("failed reading config for project %s: %v", app.Name, err)
@@ -80,6 +94,24 @@ func isZero(v reflect.Value) bool {
 	return v.Interface() == z.Interface()
 }
 
+// renderEffectiveConfig renders the effective config as YAML, with the
+// source of each key as a comment.
+func renderEffectiveConfig(effective []ddevapp.ConfigKeySource) string {
+	var lines []string
+	for _, e := range effective {
+		out, err := yaml.Marshal(map[string]interface{}{e.Key: e.Value})
+		if err != nil {
+			util.Warning("Unable to render %s: %v", e.Key, err)
+			continue
+		}
+		keyLines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+		keyLines[0] += "  # " + e.Source
+		lines = append(lines, keyLines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
 func init() {
+	DebugConfigYamlCmd.Flags().BoolVar(&configYamlEffective, "effective", false, "Print the merged configuration, with the config file or default each value comes from")
 	DebugCmd.AddCommand(DebugConfigYamlCmd)
 }
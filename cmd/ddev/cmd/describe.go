@@ -23,6 +23,7 @@ additional services like MailHog and phpMyAdmin. You can run 'ddev describe' fro
 a project directory to stop that project, or you can specify a project to describe by
 running 'ddev stop <projectname>.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		output.StartResult("describe")
 		if len(args) > 1 {
 			util.Failed("Too many arguments provided. Please use 'ddev describe' or 'ddev describe [projectname]'")
 		}
@@ -41,10 +42,17 @@ running 'ddev stop <projectname>.`,
 		if err != nil {
 			util.Failed("Failed to describe project %s: %v", project.Name, err)
 		}
+		// The actual xdebug status needs a docker exec, so it's only added
+		// here and not in Describe(), which 'ddev list' uses for every project.
+		if desc["status"] == ddevapp.SiteRunning {
+			if xdebugStatus, err := project.GetXdebugStatus(); err == nil {
+				desc["xdebug_status"] = xdebugStatus
+			}
+		}
 
 		renderedDesc, err := renderAppDescribe(desc)
 		util.CheckErr(err) // We shouldn't ever end up with an unrenderable desc.
-		output.UserOut.WithField("raw", desc).Print(renderedDesc)
+		output.EmitResult(renderedDesc, desc)
 	},
 }
 
@@ -65,6 +73,9 @@ func renderAppDescribe(desc map[string]interface{}) (string, error) {
 		output = output + "\n\nProject Information\n-----------------\n"
 		siteInfo := uitable.New()
 		siteInfo.AddRow("PHP version:", desc["php_version"])
+		if xdebugStatus, ok := desc["xdebug_status"]; ok {
+			siteInfo.AddRow("Xdebug:", xdebugStatus)
+		}
 
 		siteInfo.AddRow("URLs:", strings.Join(desc["urls"].([]string), ", "))
 		output = output + fmt.Sprint(siteInfo)
@@ -91,13 +102,19 @@ func renderAppDescribe(desc map[string]interface{}) (string, error) {
 		}
 		output = output + "\n\nOther Services\n--------------\n"
 		other := uitable.New()
-		other.AddRow("MailHog:", desc["mailhog_url"])
+		if _, ok := desc["mailhog_url"]; ok {
+			other.AddRow("MailHog:", desc["mailhog_url"])
+		}
 		if _, ok := desc["phpmyadmin_url"]; ok {
 			other.AddRow("phpMyAdmin:", desc["phpmyadmin_url"])
 		}
 		output = output + fmt.Sprint(other)
 
-		output = output + "\n" + ddevapp.RenderRouterStatus() + "\t" + ddevapp.RenderSSHAuthStatus()
+		if desc["router_status"] == ddevapp.RouterDisabled {
+			output = output + "\n\nDDEV ROUTER STATUS: " + ddevapp.RouterDisabled + "\t" + ddevapp.RenderSSHAuthStatus()
+		} else {
+			output = output + "\n" + ddevapp.RenderRouterStatus() + "\t" + ddevapp.RenderSSHAuthStatus()
+		}
 	}
 
 	return output, nil
@@ -15,6 +15,7 @@ import (
 
 	"github.com/drud/ddev/pkg/ddevapp"
 	"github.com/drud/ddev/pkg/exec"
+	"github.com/drud/ddev/pkg/output"
 	"github.com/drud/ddev/pkg/testcommon"
 	"github.com/drud/ddev/pkg/util"
 	log "github.com/sirupsen/logrus"
@@ -107,6 +108,9 @@ func TestCmdDescribe(t *testing.T) {
 		assert.EqualValues(v.Dir, raw["approot"].(string))
 
 		assert.NotEmpty(item["msg"])
+
+		result := requireJSONResult(t, out, "describe")
+		assert.Equal(raw, result["results"])
 	}
 }
 
@@ -215,6 +219,24 @@ func unmarshalJSONLogs(in string) ([]log.Fields, error) {
 	return logData, nil
 }
 
+// requireJSONResult makes sure the last line of the JSON output of a
+// command is its result, with the fields of the result schema, and returns it.
+func requireJSONResult(t *testing.T, out string, command string) log.Fields {
+	logItems, err := unmarshalJSONLogs(out)
+	require.NoError(t, err, "Unable to unmarshall ===\n%s\n===\n", out)
+	require.NotEmpty(t, logItems)
+
+	result := logItems[len(logItems)-1]
+	for _, key := range []string{"schema_version", "command", "status", "messages", "results", "errors"} {
+		require.Contains(t, result, key, "last line of output is not a result ===\n%s\n===\n", out)
+	}
+	require.EqualValues(t, output.ResultSchemaVersion, result["schema_version"])
+	require.Equal(t, command, result["command"])
+	require.Equal(t, output.ResultSuccess, result["status"], "result errors: %v", result["errors"])
+	require.Empty(t, result["errors"])
+	return result
+}
+
 // TestCmdDescribeMissingProjectDirectory ensures the `ddev describe` command returns the expected help text when
 // a project's directory no longer exists.
 func TestCmdDescribeMissingProjectDirectory(t *testing.T) {
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/ddevdns"
+	"github.com/drud/ddev/pkg/dockerutil"
+	"github.com/drud/ddev/pkg/globalconfig"
+	"github.com/drud/ddev/pkg/output"
+	"github.com/drud/ddev/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// dnsListenAddr is the address the ddev resolver listens on
+	dnsListenAddr string
+	// dnsExtraTLDs are served in addition to the TLDs of known projects
+	dnsExtraTLDs []string
+	// dnsIP overrides the address that names resolve to
+	dnsIP string
+)
+
+// dnsRefreshInterval is how often the resolver rereads the list of project TLDs
+const dnsRefreshInterval = 30 * time.Second
+
+// DNSCmd is the top-level "ddev dns" command
+var DNSCmd = &cobra.Command{
+	Use:   "dns [command]",
+	Short: "Run a local DNS resolver for project hostnames",
+	Long: `ddev can run a small local DNS resolver that answers for every hostname under the
+project TLDs (ddev.site and any project_tld you use), so projects resolve without
+network access and without hosts file edits. Run it with 'ddev dns serve' and
+configure your system to use it with the output of 'ddev dns instructions'.
+The resolver listens on ` + ddevdns.DefaultListenAddr + ` unless given --listen; the instructions
+use the same address, so pass the same --listen to both.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		err := cmd.Usage()
+		util.CheckErr(err)
+	},
+}
+
+// DNSServeCmd implements "ddev dns serve"
+var DNSServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run the local DNS resolver in the foreground",
+	Example: `ddev dns serve
+ddev dns serve --listen=127.0.0.1:5400 --tld=example.test`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			util.Failed("This command takes no additional arguments")
+		}
+
+		ip := dnsIP
+		if ip == "" {
+			var err error
+			ip, err = dockerutil.GetDockerIP()
+			if err != nil {
+				util.Failed("Failed to get Docker IP: %v", err)
+			}
+		}
+
+		server, err := ddevdns.NewServer(ip, dnsDomains())
+		if err != nil {
+			util.Failed("Failed to create DNS resolver: %v", err)
+		}
+		err = server.Listen(dnsListenAddr)
+		if err != nil {
+			util.Failed("Failed to listen on %s: %v", dnsListenAddr, err)
+		}
+
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sigs
+			_ = server.Close()
+		}()
+
+		// New projects and changed project_tld values are picked up without a restart.
+		go func() {
+			for range time.Tick(dnsRefreshInterval) {
+				if err := globalconfig.ReadGlobalConfig(); err != nil {
+					continue
+				}
+				server.SetDomains(dnsDomains())
+			}
+		}()
+
+		output.UserOut.Printf("ddev DNS resolver listening on %s (udp), resolving *.%s to %s", server.Addr(), strings.Join(server.Domains(), ", *."), ip)
+		err = server.Serve()
+		if err != nil {
+			util.Failed("DNS resolver failed: %v", err)
+		}
+	},
+}
+
+// DNSInstructionsCmd implements "ddev dns instructions"
+var DNSInstructionsCmd = &cobra.Command{
+	Use:     "instructions",
+	Short:   "Show how to configure this system to use the local DNS resolver",
+	Example: "ddev dns instructions",
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 0 {
+			util.Failed("This command takes no additional arguments")
+		}
+		output.UserOut.Print(ddevdns.ResolverInstructions(dnsListenAddr, dnsDomains(), runtime.GOOS))
+	},
+}
+
+// dnsDomains returns the TLDs of all known projects plus any requested with --tld.
+func dnsDomains() []string {
+	return append(ddevapp.GetProjectTLDs(), dnsExtraTLDs...)
+}
+
+func init() {
+	for _, c := range []*cobra.Command{DNSServeCmd, DNSInstructionsCmd} {
+		c.Flags().StringVar(&dnsListenAddr, "listen", ddevdns.DefaultListenAddr, "Address and port the resolver listens on")
+		c.Flags().StringSliceVar(&dnsExtraTLDs, "tld", []string{}, "Additional TLDs to resolve, besides those of known projects")
+	}
+	DNSServeCmd.Flags().StringVar(&dnsIP, "ip", "", "IP address names resolve to (defaults to the Docker IP)")
+
+	DNSCmd.AddCommand(DNSServeCmd)
+	DNSCmd.AddCommand(DNSInstructionsCmd)
+	RootCmd.AddCommand(DNSCmd)
+}
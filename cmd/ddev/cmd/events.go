@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/nodeps"
+	"github.com/drud/ddev/pkg/output"
+	"github.com/drud/ddev/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// DdevEventsCmd implements the ddev events command
+var DdevEventsCmd = &cobra.Command{
+	Use:   "events [projectname ...]",
+	Short: "Print project state changes as they happen",
+	Long: `Print an event whenever a project changes state, until interrupted. The events are
+starting, healthy, unhealthy, paused (containers stopped) and stopped (containers removed).
+Only the events of the given projects are printed, or of all projects if none are given.
+With -j each event is a line of JSON, for tools that want to follow the projects without
+polling 'ddev list --continuous'.`,
+	Example: `ddev events
+ddev events -j d8git`,
+	Run: func(cmd *cobra.Command, args []string) {
+		stop := make(chan struct{})
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sigs
+			close(stop)
+		}()
+
+		events := make(chan ddevapp.ProjectEvent)
+		errs := make(chan error, 1)
+		go func() {
+			errs <- ddevapp.WatchProjectEvents(stop, events)
+		}()
+
+		for {
+			select {
+			case event := <-events:
+				if len(args) > 0 && !nodeps.ArrayContainsString(args, event.Project) {
+					continue
+				}
+				output.UserOut.WithField("raw", event).Printf("%s  %s  %s", event.Time.Format(time.RFC3339), event.Project, event.Event)
+			case err := <-errs:
+				if err != nil {
+					util.Failed("Failed to watch project events: %v", err)
+				}
+				return
+			}
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(DdevEventsCmd)
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	oexec "os/exec"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/exec"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCmdEvents makes sure ddev events -j reports a project being paused and
+// started again.
+func TestCmdEvents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping TestCmdEvents because Windows stdout capture doesn't work.")
+	}
+	assert := asrt.New(t)
+
+	err := addSites()
+	require.NoError(t, err)
+	site := DevTestSites[0]
+
+	cmd := oexec.Command(DdevBin, "events", "-j", site.Name)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	err = cmd.Start()
+	require.NoError(t, err)
+	// nolint: errcheck
+	defer cmd.Process.Kill()
+
+	events := make(chan string, 100)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		for scanner.Scan() {
+			var line struct {
+				Raw ddevapp.ProjectEvent `json:"raw"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &line) == nil && line.Raw.Project == site.Name {
+				events <- line.Raw.Event
+			}
+		}
+		close(events)
+	}()
+	// Give ddev events time to subscribe to the docker events.
+	time.Sleep(2 * time.Second)
+
+	waitForEvent := func(want string) {
+		timeout := time.After(2 * time.Minute)
+		for {
+			select {
+			case event, ok := <-events:
+				require.True(t, ok, "ddev events exited before %s", want)
+				if event == want {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("Timed out waiting for the %s event of %s", want, site.Name)
+			}
+		}
+	}
+
+	out, err := exec.RunCommand(DdevBin, []string{"pause", site.Name})
+	assert.NoError(err, "ddev pause failed: %v, output: %s", err, out)
+	waitForEvent(ddevapp.ProjectEventPaused)
+
+	out, err = exec.RunCommand(DdevBin, []string{"start", site.Name})
+	assert.NoError(err, "ddev start failed: %v, output: %s", err, out)
+	waitForEvent(ddevapp.ProjectEventStarting)
+	waitForEvent(ddevapp.ProjectEventHealthy)
+}
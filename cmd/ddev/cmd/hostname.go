@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/drud/ddev/pkg/ddevhosts"
 	"github.com/drud/ddev/pkg/util"
 
 	"github.com/drud/ddev/pkg/output"
@@ -10,22 +11,38 @@ import (
 
 	"github.com/drud/ddev/pkg/ddevapp"
 	"github.com/drud/ddev/pkg/dockerutil"
-	"github.com/lextoumbourou/goodhosts"
 	"github.com/spf13/cobra"
 )
 
 var removeHostName bool
 var removeInactive bool
+var listHostNames bool
+var hostsFilePath string
 
 // HostNameCmd represents the hostname command
 var HostNameCmd = &cobra.Command{
-	Use:   "hostname [hostname] [ip]",
+	Use:   "hostname [hostname...] [ip]",
 	Short: "Manage your hostfile entries.",
 	Long: `Manage your hostfile entries. Managing host names has security and usability
 implications and requires elevated privileges. You may be asked for a password
-to allow ddev to modify your hosts file.`,
+to allow ddev to modify your hosts file.
+
+ddev keeps its entries in a section of the hosts file delimited by
+"` + ddevhosts.ManagedBlockBegin + `" and
+"` + ddevhosts.ManagedBlockEnd + `" comments, which it rewrites as a whole.
+Use --hosts-file to work on a file other than the system hosts file; unlike
+$` + ddevhosts.HostsFileEnv + `, it is kept when running with sudo.`,
+	Example: `ddev hostname mysite.ddev.site 127.0.0.1
+ddev hostname mysite.ddev.site www.mysite.ddev.site 127.0.0.1
+ddev hostname --remove mysite.ddev.site 127.0.0.1
+ddev hostname --remove-inactive
+ddev hostname --list
+ddev hostname --hosts-file /tmp/hosts mysite.ddev.site 127.0.0.1`,
 	Run: func(cmd *cobra.Command, args []string) {
-		hosts, err := goodhosts.NewHosts()
+		if hostsFilePath == "" {
+			hostsFilePath = ddevhosts.GetHostsFilePath()
+		}
+		hosts, err := ddevhosts.NewWithPath(hostsFilePath)
 		if err != nil {
 			rawResult := make(map[string]interface{})
 			detail := fmt.Sprintf("Could not open hosts file for reading: %v", err)
@@ -36,10 +53,20 @@ to allow ddev to modify your hosts file.`,
 			return
 		}
 
+		// Listing only needs to read the hosts file
+		if listHostNames {
+			if len(args) > 0 {
+				output.UserOut.Fatal("Invalid arguments supplied. 'ddev hostname --list' accepts no arguments.")
+			}
+			listManagedHostnames(hosts)
+
+			return
+		}
+
 		// Attempt to write the hosts file first to catch any permissions issues early
-		if err := hosts.Flush(); err != nil {
+		if !hosts.IsWritable() {
 			rawResult := make(map[string]interface{})
-			detail := fmt.Sprintf("Please use sudo or execute with administrative privileges: %v", err)
+			detail := fmt.Sprintf("Please use sudo or execute with administrative privileges: unable to write %s", hosts.Path)
 			rawResult["error"] = "WRITEERROR"
 			rawResult["full_error"] = detail
 			output.UserOut.WithField("raw", rawResult).Fatal(detail)
@@ -50,40 +77,51 @@ to allow ddev to modify your hosts file.`,
 		// If requested, remove all inactive host names and exit
 		if removeInactive {
 			if len(args) > 0 {
-				output.UserOut.Fatal("Invalid arguments supplied. 'ddev hostname --remove-all' accepts no arguments.")
+				output.UserOut.Fatal("Invalid arguments supplied. 'ddev hostname --remove-inactive' accepts no arguments.")
 			}
 
-			util.Warning("Attempting to remove inactive hostnames which use TLD %s", ddevapp.DdevDefaultTLD)
+			util.Warning("Attempting to remove inactive hostnames")
 			removeInactiveHostnames(hosts)
 
 			return
 		}
 
-		// If operating on one host name, two arguments are required
-		if len(args) != 2 {
-			output.UserOut.Fatal("Invalid arguments supplied. Please use 'ddev hostname [hostname] [ip]'")
+		// If operating on host names, at least one host name and the IP are required
+		if len(args) < 2 {
+			output.UserOut.Fatal("Invalid arguments supplied. Please use 'ddev hostname [hostname...] [ip]'")
 		}
 
-		hostname, ip := args[0], args[1]
+		hostnames, ip := args[:len(args)-1], args[len(args)-1]
 
-		// If requested, remove the provided host name and exit
+		// If requested, remove the provided host names and exit
 		if removeHostName {
-			removeHostname(hosts, ip, hostname)
+			removeHostnames(hosts, ip, hostnames)
 
 			return
 		}
 
-		// By default, add a host name
-		addHostname(hosts, ip, hostname)
+		// By default, add host names
+		addHostnames(hosts, ip, hostnames)
 	},
 }
 
-// addHostname encapsulates the logic of adding a hostname to the system's hosts file.
-func addHostname(hosts goodhosts.Hosts, ip, hostname string) {
+// addHostnames encapsulates the logic of adding hostnames to the ddev-managed
+// section of the system's hosts file.
+func addHostnames(hosts *ddevhosts.DdevHosts, ip string, hostnames []string) {
 	var detail string
 	rawResult := make(map[string]interface{})
 
-	if hosts.Has(ip, hostname) {
+	entries := hosts.ManagedEntries()
+	added := []string{}
+	for _, hostname := range hostnames {
+		if hosts.HasManaged(ip, hostname) {
+			continue
+		}
+		entries = append(entries, ddevhosts.Entry{IP: ip, Hostname: hostname})
+		added = append(added, hostname)
+	}
+
+	if len(added) == 0 {
 		detail = "Hostname already exists in hosts file"
 		rawResult["error"] = "SUCCESS"
 		rawResult["detail"] = detail
@@ -92,8 +130,8 @@ func addHostname(hosts goodhosts.Hosts, ip, hostname string) {
 		return
 	}
 
-	if err := hosts.Add(ip, hostname); err != nil {
-		detail = fmt.Sprintf("Could not add hostname %s at %s: %v", hostname, ip, err)
+	if err := hosts.SetManagedEntries(entries); err != nil {
+		detail = fmt.Sprintf("Could not add hostnames %s at %s: %v", strings.Join(added, ", "), ip, err)
 		rawResult["error"] = "ADDERROR"
 		rawResult["full_error"] = detail
 		output.UserOut.WithField("raw", rawResult).Fatal(detail)
@@ -101,16 +139,9 @@ func addHostname(hosts goodhosts.Hosts, ip, hostname string) {
 		return
 	}
 
-	if err := hosts.Flush(); err != nil {
-		detail = fmt.Sprintf("Could not write hosts file: %v", err)
-		rawResult["error"] = "WRITEERROR"
-		rawResult["full_error"] = detail
-		output.UserOut.WithField("raw", rawResult).Fatal(detail)
+	writeHostsFile(hosts)
 
-		return
-	}
-
-	detail = "Hostname added to hosts file"
+	detail = fmt.Sprintf("Hostname added to hosts file: %s", strings.Join(added, ", "))
 	rawResult["error"] = "SUCCESS"
 	rawResult["detail"] = detail
 	output.UserOut.WithField("raw", rawResult).Info(detail)
@@ -118,12 +149,20 @@ func addHostname(hosts goodhosts.Hosts, ip, hostname string) {
 	return
 }
 
-// removeHostname encapsulates the logic of removing a hostname from the system's hosts file.
-func removeHostname(hosts goodhosts.Hosts, ip, hostname string) {
+// removeHostnames encapsulates the logic of removing hostnames from the system's hosts file,
+// whether they're in the ddev-managed section or were added outside of it.
+func removeHostnames(hosts *ddevhosts.DdevHosts, ip string, hostnames []string) {
 	var detail string
 	rawResult := make(map[string]interface{})
 
-	if !hosts.Has(ip, hostname) {
+	remove := make(map[string]bool)
+	for _, hostname := range hostnames {
+		if hosts.Has(ip, hostname) {
+			remove[hostname] = true
+		}
+	}
+
+	if len(remove) == 0 {
 		detail = "Hostname does not exist in hosts file"
 		rawResult["error"] = "SUCCESS"
 		rawResult["detail"] = detail
@@ -132,24 +171,29 @@ func removeHostname(hosts goodhosts.Hosts, ip, hostname string) {
 		return
 	}
 
-	if err := hosts.Remove(ip, hostname); err != nil {
-		detail = fmt.Sprintf("Could not remove hostname %s at %s: %v", hostname, ip, err)
-		rawResult["error"] = "REMOVEERROR"
-		rawResult["full_error"] = detail
-		output.UserOut.WithField("raw", rawResult).Fatal(detail)
-
-		return
+	entries := []ddevhosts.Entry{}
+	for _, entry := range hosts.ManagedEntries() {
+		if entry.IP == ip && remove[entry.Hostname] {
+			continue
+		}
+		entries = append(entries, entry)
 	}
 
-	if err := hosts.Flush(); err != nil {
-		detail = fmt.Sprintf("Could not write hosts file: %v", err)
-		rawResult["error"] = "WRITEERROR"
+	err := hosts.SetManagedEntries(entries)
+	if err == nil {
+		err = hosts.Remove(ip, hostnames...)
+	}
+	if err != nil {
+		detail = fmt.Sprintf("Could not remove hostnames %s at %s: %v", strings.Join(hostnames, ", "), ip, err)
+		rawResult["error"] = "REMOVEERROR"
 		rawResult["full_error"] = detail
 		output.UserOut.WithField("raw", rawResult).Fatal(detail)
 
 		return
 	}
 
+	writeHostsFile(hosts)
+
 	detail = "Hostname removed from hosts file"
 	rawResult["error"] = "SUCCESS"
 	rawResult["detail"] = detail
@@ -158,17 +202,22 @@ func removeHostname(hosts goodhosts.Hosts, ip, hostname string) {
 	return
 }
 
-// removeInactiveHostnames will remove all host names except those current in use by active projects.
-func removeInactiveHostnames(hosts goodhosts.Hosts) {
+// removeInactiveHostnames rewrites the ddev-managed section of the hosts file so it
+// contains only the host names of active projects. Host names of inactive projects
+// that were added outside the managed section by older versions of ddev are removed,
+// and those of active projects are moved into the managed section.
+func removeInactiveHostnames(hosts *ddevhosts.DdevHosts) {
 	var detail string
 	rawResult := make(map[string]interface{})
 
 	// Get the list active hosts names to preserve
 	activeHostNames := make(map[string]bool)
+	activeTLDs := map[string]bool{ddevapp.DdevDefaultTLD: true}
 	for _, app := range ddevapp.GetActiveProjects() {
 		for _, h := range app.GetHostnames() {
 			activeHostNames[h] = true
 		}
+		activeTLDs[app.ProjectTLD] = true
 	}
 
 	// Find all current host names for the local IP address
@@ -180,58 +229,102 @@ func removeInactiveHostnames(hosts goodhosts.Hosts) {
 		output.UserOut.WithField("raw", rawResult).Fatal(detail)
 	}
 
-	// Iterate through each host line
-	for _, line := range hosts.Lines {
-		// Checking if it concerns the local IP address
-		if line.IP == dockerIP {
-			// Iterate through each registered host
-			for _, h := range line.Hosts {
-				internalResult := make(map[string]interface{})
-
-				// Ignore those we want to preserve
-				if isActiveHost := activeHostNames[h]; isActiveHost {
-					detail = fmt.Sprintf("Hostname %s at %s is active, preserving", h, line.IP)
-					internalResult["error"] = "SUCCESS"
-					internalResult["detail"] = detail
-					output.UserOut.WithField("raw", internalResult).Info(detail)
-					continue
-				}
-
-				// Silently ignore those that may not be ddev-managed
-				if !strings.HasSuffix(h, ddevapp.DdevDefaultTLD) {
-					continue
-				}
-
-				// Remaining host names are fair game to be removed
-				if err := hosts.Remove(line.IP, h); err != nil {
-					detail = fmt.Sprintf("Could not remove hostname %s at %s: %v", h, line.IP, err)
-					internalResult["error"] = "REMOVEERROR"
-					internalResult["full_error"] = detail
-					output.UserOut.WithField("raw", internalResult).Fatal(detail)
-				}
-
-				detail = fmt.Sprintf("Removed hostname %s at %s", h, line.IP)
-				internalResult["error"] = "SUCCESS"
-				internalResult["detail"] = detail
-				output.UserOut.WithField("raw", internalResult).Info(detail)
-			}
+	candidates := hosts.ManagedEntries()
+	for _, h := range hosts.UnmanagedHostnames(dockerIP) {
+		// Silently ignore unmanaged names that may not be ddev's
+		if !hasAnySuffix(h, activeTLDs) {
+			continue
 		}
+		candidates = append(candidates, ddevhosts.Entry{IP: dockerIP, Hostname: h})
 	}
 
-	if err := hosts.Flush(); err != nil {
-		detail = fmt.Sprintf("Could not write hosts file: %v", err)
-		rawResult["error"] = "WRITEERROR"
+	keep := []ddevhosts.Entry{}
+	stale := make(map[string][]string)
+	for _, entry := range candidates {
+		internalResult := make(map[string]interface{})
+
+		// Ignore those we want to preserve
+		if activeHostNames[entry.Hostname] {
+			detail = fmt.Sprintf("Hostname %s at %s is active, preserving", entry.Hostname, entry.IP)
+			internalResult["error"] = "SUCCESS"
+			internalResult["detail"] = detail
+			output.UserOut.WithField("raw", internalResult).Info(detail)
+			keep = append(keep, entry)
+			continue
+		}
+
+		// Remaining host names are fair game to be removed
+		stale[entry.IP] = append(stale[entry.IP], entry.Hostname)
+		detail = fmt.Sprintf("Removed hostname %s at %s", entry.Hostname, entry.IP)
+		internalResult["error"] = "SUCCESS"
+		internalResult["detail"] = detail
+		output.UserOut.WithField("raw", internalResult).Info(detail)
+	}
+
+	err = hosts.SetManagedEntries(keep)
+	for ip, hostnames := range stale {
+		if err != nil {
+			break
+		}
+		err = hosts.Remove(ip, hostnames...)
+	}
+	if err != nil {
+		detail = fmt.Sprintf("Could not remove inactive hostnames: %v", err)
+		rawResult["error"] = "REMOVEERROR"
 		rawResult["full_error"] = detail
 		output.UserOut.WithField("raw", rawResult).Fatal(detail)
 	}
 
+	writeHostsFile(hosts)
+
 	return
 }
 
+// listManagedHostnames shows the entries in the ddev-managed section of the hosts file.
+func listManagedHostnames(hosts *ddevhosts.DdevHosts) {
+	entries := hosts.ManagedEntries()
+	rawResult := make(map[string]interface{})
+	rawResult["hosts_file"] = hosts.Path
+	rawResult["entries"] = entries
+
+	var lines []string
+	for _, entry := range entries {
+		lines = append(lines, fmt.Sprintf("%s %s", entry.IP, entry.Hostname))
+	}
+	detail := fmt.Sprintf("No ddev-managed entries in %s", hosts.Path)
+	if len(lines) > 0 {
+		detail = fmt.Sprintf("ddev-managed entries in %s:\n%s", hosts.Path, strings.Join(lines, "\n"))
+	}
+	output.UserOut.WithField("raw", rawResult).Print(detail)
+}
+
+// writeHostsFile atomically writes the hosts file, exiting on failure.
+func writeHostsFile(hosts *ddevhosts.DdevHosts) {
+	if err := hosts.WriteAtomic(); err != nil {
+		rawResult := make(map[string]interface{})
+		detail := fmt.Sprintf("Could not write hosts file: %v", err)
+		rawResult["error"] = "WRITEERROR"
+		rawResult["full_error"] = detail
+		output.UserOut.WithField("raw", rawResult).Fatal(detail)
+	}
+}
+
+// hasAnySuffix returns true if hostname ends with any of the tlds.
+func hasAnySuffix(hostname string, tlds map[string]bool) bool {
+	for tld := range tlds {
+		if tld != "" && strings.HasSuffix(hostname, "."+tld) {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
 	HostNameCmd.Flags().BoolVarP(&removeHostName, "remove", "r", false, "Remove the provided host name - ip correlation")
 	HostNameCmd.Flags().BoolVarP(&removeInactive, "remove-inactive", "R", false, "Remove host names of inactive projects")
 	HostNameCmd.Flags().BoolVar(&removeInactive, "fire-bazooka", false, "Alias of --remove-inactive")
+	HostNameCmd.Flags().BoolVarP(&listHostNames, "list", "l", false, "List the host names in the ddev-managed section of the hosts file")
+	HostNameCmd.Flags().StringVar(&hostsFilePath, "hosts-file", "", "The hosts file to manage instead of the system hosts file or $"+ddevhosts.HostsFileEnv)
 	_ = HostNameCmd.Flags().MarkHidden("fire-bazooka")
 
 	RootCmd.AddCommand(HostNameCmd)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/drud/ddev/pkg/ddevapp"
 	"github.com/drud/ddev/pkg/dockerutil"
+	"github.com/drud/ddev/pkg/output"
 	"github.com/drud/ddev/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +32,7 @@ can be provided if it is not located at the top level of the archive.`,
 		dockerutil.EnsureDdevNetwork()
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		output.StartResult("import-db")
 		app, err := ddevapp.GetActiveApp("")
 		if err != nil {
 			util.Failed("Failed to import database: %v", err)
@@ -48,6 +50,7 @@ can be provided if it is not located at the top level of the archive.`,
 			util.Failed("Failed to import database for %s: %v", app.GetName(), err)
 		}
 		util.Success("Successfully imported database for %v", app.GetName())
+		output.EmitResult("", map[string]interface{}{"name": app.GetName(), "source": dbSource, "extract_path": dbExtPath})
 	},
 }
 
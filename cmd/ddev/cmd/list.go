@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/drud/ddev/pkg/ddevapp"
@@ -18,47 +20,146 @@ var activeOnly bool
 // continuousSleepTime is time to sleep between reads with --continuous
 var continuousSleepTime = 1
 
+// listOptions are the filters and sort order of the listed projects
+var listOptions ddevapp.ListOptions
+
+// listColumns are the columns of the project table
+var listColumns []string
+
+// listFormat, if set, is a Go template to render each project with instead of the table
+var listFormat string
+
 // DdevListCmd represents the list command
 var DdevListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List projects",
-	Long:  `List projects. Shows active projects by default, includes stopped projects with --all`,
+	Long: `List projects. Shows active projects by default, includes stopped projects with --all.
+
+Projects can be filtered with --type, --status and --name-glob, and sorted with --sort.
+Use --columns to choose the columns of the table, or --format to render each project
+with a Go template instead, for example --format='{{ .name }} {{ .httpsurl }}'. The
+template can use the keys of 'ddev describe -j' output.`,
+	Example: `ddev list --status=running --sort=type
+ddev list --type=drupal8,drupal7 --name-glob='client-*'
+ddev list --columns=name,status,php_version
+ddev list --format='{{ .name }}: {{ .approot }}'`,
 	Run: func(cmd *cobra.Command, args []string) {
-		for {
-			apps, err := ddevapp.GetProjects(activeOnly)
-			if err != nil {
-				util.Failed("failed getting GetProjects: %v", err)
+		if err := listOptions.Validate(); err != nil {
+			util.Failed("Invalid list options: %v", err)
+		}
+		for _, column := range listColumns {
+			if !ddevapp.IsValidListColumn(column) {
+				columns := ddevapp.GetValidListColumns()
+				sort.Strings(columns)
+				util.Failed("Invalid column '%s', valid columns are %s", column, strings.Join(columns, ", "))
 			}
-			appDescs := make([]map[string]interface{}, 0)
-
-			if len(apps) < 1 {
-				output.UserOut.WithField("raw", appDescs).Println("No ddev projects were found.")
-			} else {
-				table := ddevapp.CreateAppTable()
-				for _, app := range apps {
-					desc, err := app.Describe()
-					if err != nil {
-						util.Error("Failed to describe project %s: %v", app.GetName(), err)
-					}
-					appDescs = append(appDescs, desc)
-					ddevapp.RenderAppRow(table, desc)
+		}
+		if len(listColumns) == 0 {
+			listColumns = ddevapp.DefaultListColumns
+		}
+
+		listProjects()
+		if !continuous {
+			return
+		}
+
+		// Print the list again whenever a project changes state, and every
+		// interval anyway, since not every change (like a config change)
+		// is a project event.
+		stop := make(chan struct{})
+		defer close(stop)
+		events := make(chan ddevapp.ProjectEvent)
+		errs := make(chan error, 1)
+		go func() {
+			errs <- ddevapp.WatchProjectEvents(stop, events)
+		}()
+		ticker := time.NewTicker(time.Duration(continuousSleepTime) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-events:
+				// A start or stop brings several events at once; list once for all of them.
+				drainProjectEvents(events)
+			case <-ticker.C:
+			case err := <-errs:
+				if err != nil {
+					util.Warning("Failed to watch project events, only listing every %d second(s): %v", continuousSleepTime, err)
 				}
-				output.UserOut.WithField("raw", appDescs).Print(table.String() + "\n" + ddevapp.RenderRouterStatus())
+				errs = nil
+				continue
 			}
+			listProjects()
+		}
+	},
+}
 
-			if !continuous {
-				break
-			}
+// listProjects prints the projects with the list options and columns.
+func listProjects() {
+	output.StartResult("list")
+	apps, err := ddevapp.GetProjects(activeOnly)
+	if err != nil {
+		util.Failed("failed getting GetProjects: %v", err)
+	}
+	appDescs := make([]map[string]interface{}, 0)
+
+	if len(apps) < 1 {
+		output.EmitResult("No ddev projects were found.", appDescs)
+		return
+	}
 
-			time.Sleep(time.Duration(continuousSleepTime) * time.Second)
+	for _, app := range apps {
+		desc, err := app.Describe()
+		if err != nil {
+			util.Error("Failed to describe project %s: %v", app.GetName(), err)
 		}
-	},
+		appDescs = append(appDescs, desc)
+	}
+	appDescs, err = ddevapp.FilterAppDescs(appDescs, listOptions)
+	if err != nil {
+		util.Failed("Failed to filter projects: %v", err)
+	}
+
+	switch {
+	case len(appDescs) < 1:
+		output.EmitResult("No ddev projects match the filters.", appDescs)
+	case listFormat != "":
+		rendered, err := ddevapp.RenderAppDescsTemplate(appDescs, listFormat)
+		if err != nil {
+			util.Failed("Failed to render projects: %v", err)
+		}
+		output.EmitResult(rendered, appDescs)
+	default:
+		table := ddevapp.CreateAppTableWithColumns(listColumns)
+		for _, desc := range appDescs {
+			ddevapp.RenderAppRowColumns(table, desc, listColumns)
+		}
+		output.EmitResult(table.String()+"\n"+ddevapp.RenderRouterStatus(), appDescs)
+	}
+}
+
+// drainProjectEvents consumes the project events that follow in quick
+// succession, so they lead to one listing.
+func drainProjectEvents(events <-chan ddevapp.ProjectEvent) {
+	for {
+		select {
+		case <-events:
+		case <-time.After(200 * time.Millisecond):
+			return
+		}
+	}
 }
 
 func init() {
 	DdevListCmd.Flags().BoolVarP(&activeOnly, "active-only", "A", false, "If set, only currently active projects will be displayed.")
-	DdevListCmd.Flags().BoolVarP(&continuous, "continuous", "", false, "If set, project information will be emitted until the command is stopped.")
-	DdevListCmd.Flags().IntVarP(&continuousSleepTime, "continuous-sleep-interval", "I", 1, "Time in seconds between ddev list --continous output lists.")
+	DdevListCmd.Flags().BoolVarP(&continuous, "continuous", "", false, "If set, project information will be emitted until the command is stopped, whenever a project changes state and every --continuous-sleep-interval. Use 'ddev events' to only be told of the changes.")
+	DdevListCmd.Flags().IntVarP(&continuousSleepTime, "continuous-sleep-interval", "I", 1, "Time in seconds between ddev list --continuous output lists when no project changes state.")
+	DdevListCmd.Flags().StringSliceVar(&listOptions.Types, "type", nil, "Only list projects of these types, for example --type=drupal8,wordpress")
+	DdevListCmd.Flags().StringSliceVar(&listOptions.Statuses, "status", nil, "Only list projects with these statuses, for example --status=running,paused")
+	DdevListCmd.Flags().StringVar(&listOptions.NameGlob, "name-glob", "", "Only list projects with names matching this shell pattern, for example --name-glob='client-*'")
+	DdevListCmd.Flags().StringVar(&listOptions.Sort, "sort", "", "Sort projects by name, status or type")
+	DdevListCmd.Flags().StringSliceVar(&listColumns, "columns", nil, "The columns to show, any of name, type, location, approot, url, urls, hostnames, status and php_version")
+	DdevListCmd.Flags().StringVar(&listFormat, "format", "", "Render each project with this Go template instead of the table")
 
 	RootCmd.AddCommand(DdevListCmd)
 }
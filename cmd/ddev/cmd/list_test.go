@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bufio"
+	"encoding/json"
+	"fmt"
 	"github.com/stretchr/testify/require"
 	"runtime"
 	"strings"
@@ -29,6 +31,8 @@ func TestCmdList(t *testing.T) {
 
 	siteList := getTestingSitesFromList(t, jsonOut)
 	assert.Equal(len(DevTestSites), len(siteList))
+	result := requireJSONResult(t, jsonOut, "list")
+	assert.Equal(result["raw"], result["results"])
 
 	for _, v := range DevTestSites {
 		app, err := ddevapp.GetActiveApp(v.Name)
@@ -86,6 +90,26 @@ func TestCmdList(t *testing.T) {
 	siteList = getTestingSitesFromList(t, jsonOut)
 	assert.Equal(len(DevTestSites), len(siteList))
 
+	// Filter out the stopped first app, and select it by name
+	jsonOut, err = exec.RunCommand(DdevBin, []string{"list", "-j", "--status=running", "--sort=name"})
+	assert.NoError(err, "error runnning ddev list: %v output=%s", jsonOut)
+	siteList = getTestingSitesFromList(t, jsonOut)
+	assert.Equal(len(DevTestSites)-1, len(siteList))
+	jsonOut, err = exec.RunCommand(DdevBin, []string{"list", "-j", "--name-glob=" + firstApp.Name})
+	assert.NoError(err, "error runnning ddev list: %v output=%s", jsonOut)
+	siteList = getTestingSitesFromList(t, jsonOut)
+	require.Len(t, siteList, 1)
+	assert.Equal(firstApp.Name, siteList[0].(map[string]interface{})["name"])
+
+	// Render the projects with a template and with chosen columns
+	out, err = exec.RunCommand(DdevBin, []string{"list", "--name-glob=" + firstApp.Name, "--format={{ .name }}:{{ .status }}"})
+	assert.NoError(err, "error runnning ddev list: %v output=%s", out)
+	assert.Equal(firstApp.Name+":"+ddevapp.SiteStopped, strings.TrimSpace(out))
+	out, err = exec.RunCommand(DdevBin, []string{"list", "--columns=name,php_version"})
+	assert.NoError(err, "error runnning ddev list: %v output=%s", out)
+	assert.Contains(out, "PHP VERSION")
+	assert.NotContains(out, "LOCATION")
+
 	// Leave firstApp running for other tests
 	err = firstApp.Start()
 	assert.NoError(err)
@@ -184,3 +208,69 @@ func TestCmdListContinuous(t *testing.T) {
 	assert.NoError(err)
 
 }
+
+// TestCmdListContinuousEvents makes sure ddev list --continuous prints the
+// list again when a project changes state, without waiting for the interval.
+func TestCmdListContinuousEvents(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping TestCmdListContinuousEvents because Windows stdout capture doesn't work.")
+	}
+	assert := asrt.New(t)
+
+	err := addSites()
+	require.NoError(t, err)
+	site := DevTestSites[0]
+
+	cmd := oexec.Command(DdevBin, "list", "-j", "--continuous", "--continuous-sleep-interval=600")
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	err = cmd.Start()
+	require.NoError(t, err)
+	// nolint: errcheck
+	defer cmd.Process.Kill()
+
+	// statuses gets the status of the site in each list that's printed.
+	statuses := make(chan string, 100)
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var line struct {
+				Raw []map[string]interface{} `json:"raw"`
+			}
+			if json.Unmarshal(scanner.Bytes(), &line) != nil {
+				continue
+			}
+			for _, desc := range line.Raw {
+				if desc["name"] == site.Name {
+					statuses <- fmt.Sprint(desc["status"])
+				}
+			}
+		}
+		close(statuses)
+	}()
+
+	waitForStatus := func(want string) {
+		timeout := time.After(2 * time.Minute)
+		for {
+			select {
+			case status, ok := <-statuses:
+				require.True(t, ok, "ddev list --continuous exited before %s was %s", site.Name, want)
+				if status == want {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("Timed out waiting for a list with %s %s", site.Name, want)
+			}
+		}
+	}
+	waitForStatus(ddevapp.SiteRunning)
+
+	out, err := exec.RunCommand(DdevBin, []string{"pause", site.Name})
+	assert.NoError(err, "ddev pause failed: %v, output: %s", err, out)
+	waitForStatus(ddevapp.SitePaused)
+
+	out, err = exec.RunCommand(DdevBin, []string{"start", site.Name})
+	assert.NoError(err, "ddev start failed: %v, output: %s", err, out)
+	waitForStatus(ddevapp.SiteRunning)
+}
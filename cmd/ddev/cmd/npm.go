@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var NpmCmd = &cobra.Command{
+	Use:   "npm [command]",
+	Short: "Executes an npm command within the web container",
+	Long: `Executes an npm command in the web container, in the directory matching
+the current directory, or at the project root when run from outside of it.
+The Node.js version is set with nodejs_version in .ddev/config.yaml. For example:
+
+ddev npm install
+ddev npm run build`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runNodeCommand("npm", args)
+	},
+}
+
+// runNodeCommand runs a Node.js tool like npm or yarn with args in the web
+// container of the active project, starting the project if needed.
+func runNodeCommand(tool string, args []string) {
+	app, err := ddevapp.GetActiveApp("")
+	if err != nil {
+		util.Failed("Failed to get active project: %v", err)
+	}
+
+	if app.SiteStatus() != ddevapp.SiteRunning {
+		if err = app.Start(); err != nil {
+			util.Failed("Failed to start %s: %v", app.Name, err)
+		}
+	}
+
+	_, _, err = app.Exec(&ddevapp.ExecOpts{
+		Service:   "web",
+		Dir:       app.GetContainerWorkingDir(),
+		Cmd:       tool + " " + util.ShellQuoteArgs(args),
+		NoCapture: true,
+	})
+	if err != nil {
+		util.Failed("%s command failed: %v", tool, err)
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(NpmCmd)
+	NpmCmd.Flags().SetInterspersed(false)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/output"
+	"github.com/drud/ddev/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// DdevProfileCmd implements the ddev profile command
+var DdevProfileCmd = &cobra.Command{
+	Use:   "profile [url]",
+	Short: "Make a profiled request to the project",
+	Long: `Make a request to the project with the configured profiler enabled.
+The url can be a full URL of the project or a path like /node/1; it defaults
+to the front page. With 'profiler: xdebug-profile' the resulting cachegrind
+files are copied into .ddev/profiles; with 'profiler: blackfire' the profile
+is sent to blackfire.io and the link to it is shown.`,
+	Example: `ddev profile
+ddev profile /node/1
+ddev profile https://myproject.ddev.site/admin`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		app, err := ddevapp.GetActiveApp("")
+		if err != nil {
+			util.Failed("Failed to get active project: %v", err)
+		}
+
+		target := ""
+		if len(args) == 1 {
+			target = args[0]
+		}
+
+		files, out, err := app.Profile(target)
+		if err != nil {
+			util.Failed("Failed to profile %s: %v", app.GetName(), err)
+		}
+
+		rawResult := map[string]interface{}{
+			"profiler": app.Profiler,
+			"files":    files,
+			"output":   out,
+		}
+		msg := out
+		if len(files) > 0 {
+			msg = out + "\nProfiles written to:\n" + strings.Join(files, "\n")
+		}
+		output.UserOut.WithField("raw", rawResult).Print(msg)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(DdevProfileCmd)
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/drud/ddev/pkg/ddevapp"
 	"github.com/drud/ddev/pkg/dockerutil"
+	"github.com/drud/ddev/pkg/output"
 	"github.com/drud/ddev/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -43,6 +44,7 @@ var PullCmd = &cobra.Command{
 }
 
 func appPull(skipConfirmation bool) {
+	output.StartResult("pull")
 	app, err := ddevapp.GetActiveApp("")
 
 	if err != nil {
@@ -55,6 +57,7 @@ func appPull(skipConfirmation bool) {
 		var message string
 		if skipDbArg && skipFilesArg {
 			util.Warning("Both database and files import steps skipped.")
+			output.EmitResult("", nil)
 			return
 		} else if !skipDbArg && skipFilesArg {
 			message = "database"
@@ -87,6 +90,14 @@ func appPull(skipConfirmation bool) {
 	}
 
 	util.Success("Pull succeeded.")
+	output.EmitResult("", map[string]interface{}{
+		"name":        app.GetName(),
+		"provider":    app.Provider,
+		"environment": envArg,
+		"skip_db":     skipDbArg,
+		"skip_files":  skipFilesArg,
+		"skip_import": skipImportArg,
+	})
 }
 
 func init() {
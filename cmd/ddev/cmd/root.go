@@ -31,7 +31,7 @@ var RootCmd = &cobra.Command{
 	Long:    "Create and maintain a local web development environment.",
 	Version: version.DdevVersion,
 	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		ignores := []string{"version", "config", "hostname", "help", "auth-pantheon", "import-files"}
+		ignores := []string{"version", "config", "hostname", "help", "auth-pantheon", "import-files", "dns"}
 		command := strings.Join(os.Args[1:], " ")
 
 		output.LogSetUp()
@@ -1,36 +1,50 @@
 package cmd
 
 import (
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/output"
 	"github.com/drud/ddev/pkg/util"
 	"github.com/spf13/cobra"
+	"sync"
 )
 
 var snapshotAll bool
 var snapshotName string
 
+// snapshotParallel is the number of projects to snapshot at the same time.
+var snapshotParallel int
+
 // DdevSnapshotCommand provides the snapshot command
 var DdevSnapshotCommand = &cobra.Command{
 	Use:   "snapshot [projectname projectname...]",
 	Short: "Create a database snapshot for one or more projects.",
 	Long:  `Uses mariabackup command to create a database snapshot in the .ddev/db_snapshots folder.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		output.StartResult("snapshot")
 		apps, err := getRequestedProjects(args, snapshotAll)
 		if err != nil {
 			util.Failed("Unable to get project(s) %v: %v", args, err)
 		}
 
-		for _, app := range apps {
-			if snapshotNameOutput, err := app.SnapshotDatabase(snapshotName); err != nil {
-				util.Failed("Failed to snapshot %s: %v", app.GetName(), err)
-			} else {
-				util.Success("Created snapshot %s", snapshotNameOutput)
-			}
-		}
+		snapshotNames := make(map[*ddevapp.DdevApp]string)
+		var lock sync.Mutex
+		results := runOnProjects("snapshot", apps, snapshotParallel, func(app *ddevapp.DdevApp) error {
+			snapshotNameOutput, err := app.SnapshotDatabase(snapshotName)
+			lock.Lock()
+			snapshotNames[app] = snapshotNameOutput
+			lock.Unlock()
+			return err
+		}, func(app *ddevapp.DdevApp) interface{} {
+			util.Success("Created snapshot %s", snapshotNames[app])
+			return map[string]interface{}{"snapshot": snapshotNames[app]}
+		})
+		output.EmitResult("", results)
 	},
 }
 
 func init() {
 	DdevSnapshotCommand.Flags().BoolVarP(&snapshotAll, "all", "a", false, "Snapshot all running sites")
 	DdevSnapshotCommand.Flags().StringVarP(&snapshotName, "name", "n", "", "provide a name for the snapshot")
+	DdevSnapshotCommand.Flags().IntVar(&snapshotParallel, "parallel", 1, "The number of projects to snapshot at the same time")
 	RootCmd.AddCommand(DdevSnapshotCommand)
 }
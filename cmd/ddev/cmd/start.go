@@ -2,7 +2,6 @@ package cmd
 
 import (
 	"github.com/drud/ddev/pkg/ddevapp"
-	"strings"
 
 	"github.com/drud/ddev/pkg/dockerutil"
 	"github.com/drud/ddev/pkg/output"
@@ -12,6 +11,9 @@ import (
 
 var startAll bool
 
+// startParallel is the number of projects to start at the same time.
+var startParallel int
+
 // StartCmd provides the ddev start command
 var StartCmd = &cobra.Command{
 	Use:     "start [projectname ...]",
@@ -25,6 +27,7 @@ any directory by running 'ddev start projectname [projectname ...]'`,
 		dockerutil.EnsureDdevNetwork()
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		output.StartResult("start")
 		projects, err := getRequestedProjects(args, startAll)
 		if err != nil {
 			util.Failed("Failed to get project(s): %v", err)
@@ -36,27 +39,28 @@ any directory by running 'ddev start projectname [projectname ...]'`,
 			util.Failed(err.Error())
 		}
 
-		for _, project := range projects {
+		results := runOnProjects("start", projects, startParallel, func(project *ddevapp.DdevApp) error {
 			if err := ddevapp.CheckForMissingProjectFiles(project); err != nil {
-				util.Failed("Failed to start %s: %v", project.GetName(), err)
+				return err
 			}
 
+			project.WarnConfigSchemaProblems(true)
 			output.UserOut.Printf("Starting %s...", project.GetName())
-			if err := project.Start(); err != nil {
-				util.Failed("Failed to start %s: %v", project.GetName(), err)
-				continue
-			}
-
+			return project.Start()
+		}, func(project *ddevapp.DdevApp) interface{} {
 			util.Success("Successfully started %s", project.GetName())
-			util.Success("Project can be reached at %s", strings.Join(project.GetAllURLs(), ", "))
+			reportProjectURLs(project)
 			if project.WebcacheEnabled {
 				util.Warning("All contents were copied to fast docker filesystem,\nbut bidirectional sync operation may not be fully functional for a few minutes.")
 			}
-		}
+			return map[string]interface{}{"urls": project.GetAllURLs()}
+		})
+		output.EmitResult("", results)
 	},
 }
 
 func init() {
 	StartCmd.Flags().BoolVarP(&startAll, "all", "a", false, "Start all stopped projects")
+	StartCmd.Flags().IntVar(&startParallel, "parallel", 1, "The number of projects to start at the same time")
 	RootCmd.AddCommand(StartCmd)
 }
@@ -47,9 +47,19 @@ func TestCmdStart(t *testing.T) {
 		startMultipleArgs = append(startMultipleArgs, app.GetName())
 	}
 
-	// Start multiple projects in one command
-	out, err = exec.RunCommand(DdevBin, startMultipleArgs)
+	// Start multiple projects in one command, with a result for each in the
+	// JSON output.
+	out, err = exec.RunCommand(DdevBin, append(startMultipleArgs, "-j"))
 	assert.NoError(err, "ddev start with multiple project names should have succeeded, but failed, err: %v, output %s", err, out)
+	result := requireJSONResult(t, out, "start")
+	results, ok := result["results"].([]interface{})
+	require.True(t, ok, "results is not a list: %v", result["results"])
+	assert.Len(results, len(apps))
+	for _, r := range results {
+		projectResult := r.(map[string]interface{})
+		assert.Equal("success", projectResult["status"])
+		assert.NotEmpty(projectResult["details"].(map[string]interface{})["urls"])
+	}
 
 	// Confirm all sites are running
 	for _, app := range apps {
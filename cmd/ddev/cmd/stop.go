@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/output"
 	"github.com/drud/ddev/pkg/util"
 	"github.com/spf13/cobra"
 )
@@ -23,6 +24,9 @@ var stopSSHAgent bool
 
 var unlist bool
 
+// stopParallel is the number of projects to stop at the same time.
+var stopParallel int
+
 // DdevStopCmd represents the remove command
 var DdevStopCmd = &cobra.Command{
 	Use:     "stop [projectname ...]",
@@ -41,6 +45,7 @@ To snapshot the database on stop, use "ddev stop --snapshot"; A snapshot is auto
 "ddev stop --remove-data" unless you use "ddev stop --remove-data --omit-snapshot".
 `,
 	Run: func(cmd *cobra.Command, args []string) {
+		output.StartResult("stop")
 		if createSnapshot && omitSnapshot {
 			util.Failed("Illegal option combination: --snapshot and --omit-snapshot:")
 		}
@@ -50,8 +55,8 @@ To snapshot the database on stop, use "ddev stop --snapshot"; A snapshot is auto
 			util.Failed("Failed to get project(s): %v", err)
 		}
 
-		// Iterate through the list of projects built above, removing each one.
-		for _, project := range projects {
+		// Remove each of the projects built above.
+		results := runOnProjects("remove project", projects, stopParallel, func(project *ddevapp.DdevApp) error {
 			if project.SiteStatus() == ddevapp.SiteStopped {
 				util.Warning("Project %s is not currently running. Try 'ddev start'.", project.GetName())
 			}
@@ -59,20 +64,23 @@ To snapshot the database on stop, use "ddev stop --snapshot"; A snapshot is auto
 			// We do the snapshot if either --snapshot or --remove-data UNLESS omit-snapshot is set
 			doSnapshot := (createSnapshot || removeData) && !omitSnapshot
 			if err := project.Stop(removeData, doSnapshot); err != nil {
-				util.Failed("Failed to remove project %s: \n%v", project.GetName(), err)
+				return err
 			}
 			if unlist {
 				project.RemoveGlobalProjectInfo()
 			}
-
+			return nil
+		}, func(project *ddevapp.DdevApp) interface{} {
 			util.Success("Project %s has been stopped.", project.GetName())
-		}
+			return nil
+		})
 
 		if stopSSHAgent {
 			if err := ddevapp.RemoveSSHAgentContainer(); err != nil {
 				util.Error("Failed to remove ddev-ssh-agent: %v", err)
 			}
 		}
+		output.EmitResult("", results)
 	},
 }
 
@@ -83,6 +91,7 @@ func init() {
 
 	DdevStopCmd.Flags().BoolVarP(&stopAll, "all", "a", false, "Stop and remove all running or container-stopped projects")
 	DdevStopCmd.Flags().BoolVarP(&stopSSHAgent, "stop-ssh-agent", "", false, "Stop the ddev-ssh-agent container")
+	DdevStopCmd.Flags().IntVar(&stopParallel, "parallel", 1, "The number of projects to stop at the same time")
 	DdevStopCmd.Flags().BoolVarP(&unlist, "unlist", "U", false, "Remove the project from global project list, it won't show in ddev list until started again")
 
 	RootCmd.AddCommand(DdevStopCmd)
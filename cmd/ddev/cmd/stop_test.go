@@ -27,9 +27,15 @@ func TestCmdStop(t *testing.T) {
 	for _, site := range DevTestSites {
 		cleanup := site.Chdir()
 
-		out, err := exec.RunCommand(DdevBin, []string{"stop"})
+		out, err := exec.RunCommand(DdevBin, []string{"stop", "-j"})
 		assert.NoError(err, "ddev stop should succeed but failed, err: %v, output: %s", err, out)
 		assert.Contains(out, "has been stopped")
+		result := requireJSONResult(t, out, "stop")
+		results, ok := result["results"].([]interface{})
+		require.True(t, ok, "results is not a list: %v", result["results"])
+		require.Len(t, results, 1)
+		assert.Equal(site.Name, results[0].(map[string]interface{})["name"])
+		assert.Equal("success", results[0].(map[string]interface{})["status"])
 
 		// Ensure the site that was just stopped does not appear in the list of sites
 		apps := ddevapp.GetActiveProjects()
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"github.com/drud/ddev/pkg/ddevapp"
 	"github.com/drud/ddev/pkg/globalconfig"
+	"github.com/drud/ddev/pkg/output"
+	"github.com/drud/ddev/pkg/util"
+	"strings"
 )
 
 // getRequestedProjects will collect and return the requested projects from command line arguments and flags.
@@ -60,3 +63,60 @@ func getRequestedProjects(names []string, all bool) ([]*ddevapp.DdevApp, error)
 
 	return requestedProjects, nil
 }
+
+// projectResult is the outcome of an operation on one project, as reported
+// in the results of the JSON output.
+type projectResult struct {
+	Name            string      `json:"name"`
+	Status          string      `json:"status"`
+	Error           string      `json:"error,omitempty"`
+	DurationSeconds float64     `json:"duration_seconds"`
+	Details         interface{} `json:"details,omitempty"`
+}
+
+// runOnProjects runs op on projects, at most parallel of them at the same
+// time, then calls onSuccess for each project op succeeded on, reports each
+// failure, and fails if op failed on any project. action is the name of the
+// operation for the messages, like "start". onSuccess can return details
+// about the project for its projectResult. The projectResults are returned
+// and set as the results of the JSON output.
+func runOnProjects(action string, projects []*ddevapp.DdevApp, parallel int, op func(*ddevapp.DdevApp) error, onSuccess func(*ddevapp.DdevApp) interface{}) []projectResult {
+	results := ddevapp.RunOnProjects(projects, parallel, op)
+	projectResults := make([]projectResult, 0, len(results))
+	for _, r := range results {
+		pr := projectResult{
+			Name:            r.App.GetName(),
+			Status:          output.ResultSuccess,
+			DurationSeconds: r.Duration.Seconds(),
+		}
+		if r.Err != nil {
+			pr.Status = output.ResultError
+			pr.Error = r.Err.Error()
+		} else if onSuccess != nil {
+			pr.Details = onSuccess(r.App)
+		}
+		projectResults = append(projectResults, pr)
+	}
+	output.SetResults(projectResults)
+
+	failed := ddevapp.FailedProjects(results)
+	for _, r := range failed {
+		util.Error("Failed to %s %s: %v", action, r.App.GetName(), r.Err)
+	}
+	if len(failed) > 0 {
+		util.Failed("Failed to %s %d of %d project(s)", action, len(failed), len(results))
+	}
+	return projectResults
+}
+
+// reportProjectURLs tells the user where a started project can be reached. The
+// URLs can be unknown, e.g. without the router when the web container hasn't
+// published any port.
+func reportProjectURLs(app *ddevapp.DdevApp) {
+	urls := app.GetAllURLs()
+	if len(urls) == 0 {
+		util.Warning("The URLs of %s are not known yet, use 'ddev describe' once its web container has published its ports", app.GetName())
+		return
+	}
+	util.Success("Project can be reached at %s", strings.Join(urls, ", "))
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/output"
+	"github.com/drud/ddev/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// DdevXdebugCmd implements the ddev xdebug command
+var DdevXdebugCmd = &cobra.Command{
+	Use:   "xdebug [on|off|status]",
+	Short: "Enable or disable xdebug in the running web container",
+	Long: `Enable or disable xdebug in the running web container without a restart.
+The change lasts until the project is restarted; to change the default use
+'ddev config --xdebug-enabled=true' (or false).`,
+	Example: `ddev xdebug on
+ddev xdebug off
+ddev xdebug status`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		app, err := ddevapp.GetActiveApp("")
+		if err != nil {
+			util.Failed("Failed to get active project: %v", err)
+		}
+
+		if app.SiteStatus() != ddevapp.SiteRunning {
+			util.Failed("Project is not currently running. Try 'ddev start'.")
+		}
+
+		mode := "status"
+		if len(args) == 1 {
+			mode = args[0]
+		}
+
+		switch mode {
+		case "on", "enable", "true":
+			err = app.SetXdebug(true)
+			if err != nil {
+				util.Failed("Failed to enable xdebug: %v", err)
+			}
+		case "off", "disable", "false":
+			err = app.SetXdebug(false)
+			if err != nil {
+				util.Failed("Failed to disable xdebug: %v", err)
+			}
+		case "status":
+		default:
+			util.Failed("Invalid argument '%s', use 'ddev xdebug on', 'ddev xdebug off' or 'ddev xdebug status'", mode)
+		}
+
+		status, err := app.GetXdebugStatus()
+		if err != nil {
+			util.Failed("%v", err)
+		}
+		rawResult := map[string]interface{}{"xdebug_status": status}
+		output.UserOut.WithField("raw", rawResult).Printf("xdebug is %s in project %s", status, app.GetName())
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(DdevXdebugCmd)
+}
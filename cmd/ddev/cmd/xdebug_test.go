@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/exec"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCmdXdebug toggles xdebug in a running project and checks the reported status.
+func TestCmdXdebug(t *testing.T) {
+	assert := asrt.New(t)
+
+	err := addSites()
+	require.NoError(t, err)
+
+	site := DevTestSites[0]
+	cleanup := site.Chdir()
+	defer cleanup()
+
+	out, err := exec.RunCommand(DdevBin, []string{"xdebug", "on"})
+	assert.NoError(err, "ddev xdebug on failed, output: %s", out)
+	assert.Contains(out, "xdebug is enabled")
+
+	out, err = exec.RunCommand(DdevBin, []string{"xdebug", "status"})
+	assert.NoError(err, "ddev xdebug status failed, output: %s", out)
+	assert.Contains(out, "xdebug is enabled")
+
+	out, err = exec.RunCommand(DdevBin, []string{"describe", "-j"})
+	assert.NoError(err, "ddev describe -j failed, output: %s", out)
+	result := requireJSONResult(t, out, "describe")
+	desc, ok := result["raw"].(map[string]interface{})
+	require.True(t, ok, "describe result has no raw description: %v", result)
+	assert.Equal(ddevapp.XdebugStatusEnabled, desc["xdebug_status"])
+
+	// ddev list doesn't pay for a docker exec per project to get it.
+	out, err = exec.RunCommand(DdevBin, []string{"list", "-j"})
+	assert.NoError(err, "ddev list -j failed, output: %s", out)
+	assert.NotContains(out, "xdebug_status")
+
+	out, err = exec.RunCommand(DdevBin, []string{"xdebug", "off"})
+	assert.NoError(err, "ddev xdebug off failed, output: %s", out)
+	assert.Contains(out, "xdebug is disabled")
+
+	out, err = exec.RunCommand(DdevBin, []string{"xdebug", "bogus"})
+	assert.Error(err)
+	assert.Contains(out, "Invalid argument")
+}
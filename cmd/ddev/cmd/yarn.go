@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var YarnCmd = &cobra.Command{
+	Use:   "yarn [command]",
+	Short: "Executes a yarn command within the web container",
+	Long: `Executes a yarn command in the web container, in the directory matching
+the current directory, or at the project root when run from outside of it.
+The Node.js version is set with nodejs_version in .ddev/config.yaml. For example:
+
+ddev yarn install
+ddev yarn run build`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runNodeCommand("yarn", args)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(YarnCmd)
+	YarnCmd.Flags().SetInterspersed(false)
+}
@@ -28,6 +28,10 @@ fi
 
 mkcert -install
 
+# Projects put their custom certificates here, on the ddev-global-cache volume,
+# so they survive the router being recreated.
+mkdir -p /mnt/ddev-global-cache/custom_certs
+
 # It's unknown what docker event causes an attempt to use these files, but they might as well exist
 # to prevent it.
 mkcert -cert-file /etc/nginx/certs/.crt -key-file /etc/nginx/certs/.key "*.ddev.local" 127.0.0.1 localhost
@@ -59,3 +59,12 @@ if [ "${OS:-$(uname)}" != "Windows_NT" ]; then
 fi
 # Make sure internal access to https is working
 docker exec -t $CONTAINER_NAME curl --fail https://localhost/healthcheck || (echo "Failed to run https healthcheck inside container" && exit 104)
+
+# Make sure a custom certificate is used for its hostname and master.crt for others and the default server
+docker run -d --rm --name ddev-router-test-web -e VIRTUAL_HOST=custom.ddev.site,other.ddev.site -e HTTP_EXPOSE=80 -e HTTPS_EXPOSE=443:80 --expose 80 busybox sleep 300
+docker exec $CONTAINER_NAME sh -c "mkdir -p /mnt/ddev-global-cache/custom_certs && cd /mnt/ddev-global-cache/custom_certs && mkcert -cert-file custom.ddev.site.crt -key-file custom.ddev.site.key custom.ddev.site"
+docker exec $CONTAINER_NAME docker-gen -only-exposed /app/nginx.tmpl /tmp/custom.conf
+docker rm -f ddev-router-test-web
+docker exec $CONTAINER_NAME grep -A100 "server_name custom.ddev.site" /tmp/custom.conf | grep -m1 "ssl_certificate " | grep "custom.ddev.site.crt" || (echo "custom.ddev.site doesn't use its custom certificate" && exit 105)
+docker exec $CONTAINER_NAME grep -A100 "server_name other.ddev.site" /tmp/custom.conf | grep -m1 "ssl_certificate " | grep "master.crt" || (echo "other.ddev.site doesn't use master.crt" && exit 106)
+if docker exec $CONTAINER_NAME grep -A30 "server_name _" /tmp/custom.conf | grep "ssl_certificate " | grep -v "master"; then echo "default server doesn't use master.crt" && exit 107; fi
@@ -0,0 +1,102 @@
+package ddevapp
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+)
+
+// ListOptions are the filters and sort order of ddev list.
+type ListOptions struct {
+	// Types, if not empty, are the project types to include.
+	Types []string
+	// Statuses, if not empty, are the project statuses to include, like
+	// "running" or "stopped".
+	Statuses []string
+	// NameGlob, if set, is a shell pattern project names must match.
+	NameGlob string
+	// Sort is the key in ValidListSorts to sort by, or empty to keep the order.
+	Sort string
+}
+
+// Validate makes sure the name glob and sort key are valid.
+func (opts ListOptions) Validate() error {
+	if _, err := path.Match(opts.NameGlob, ""); err != nil {
+		return fmt.Errorf("invalid name glob '%s': %v", opts.NameGlob, err)
+	}
+	if !IsValidListSort(opts.Sort) {
+		sorts := GetValidListSorts()
+		sort.Strings(sorts)
+		return fmt.Errorf("invalid sort '%s', valid values are %s", opts.Sort, strings.Join(sorts, ", "))
+	}
+	return nil
+}
+
+// FilterAppDescs returns the project descriptions, as returned by
+// app.Describe(), that pass the filters of opts, sorted as opts says.
+func FilterAppDescs(descs []map[string]interface{}, opts ListOptions) ([]map[string]interface{}, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	filtered := make([]map[string]interface{}, 0, len(descs))
+	for _, desc := range descs {
+		name := fmt.Sprint(desc["name"])
+		if opts.NameGlob != "" {
+			if match, _ := path.Match(opts.NameGlob, name); !match {
+				continue
+			}
+		}
+		if len(opts.Types) > 0 && !listContains(opts.Types, fmt.Sprint(desc["type"])) {
+			continue
+		}
+		if len(opts.Statuses) > 0 && !listContains(opts.Statuses, fmt.Sprint(desc["status"])) {
+			continue
+		}
+		filtered = append(filtered, desc)
+	}
+
+	if opts.Sort != "" {
+		sort.SliceStable(filtered, func(i, j int) bool {
+			a, b := fmt.Sprint(filtered[i][opts.Sort]), fmt.Sprint(filtered[j][opts.Sort])
+			if a != b {
+				return a < b
+			}
+			return fmt.Sprint(filtered[i]["name"]) < fmt.Sprint(filtered[j]["name"])
+		})
+	}
+	return filtered, nil
+}
+
+// listContains tells whether value is in values, ignoring case.
+func listContains(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderAppDescsTemplate renders each project description with the Go
+// template format, which can use the sprig functions, one project per line.
+func RenderAppDescsTemplate(descs []map[string]interface{}, format string) (string, error) {
+	tmpl, err := template.New("list").Funcs(sprig.TxtFuncMap()).Parse(format)
+	if err != nil {
+		return "", fmt.Errorf("invalid format template: %v", err)
+	}
+
+	var out bytes.Buffer
+	for _, desc := range descs {
+		if err := tmpl.Execute(&out, desc); err != nil {
+			return "", fmt.Errorf("failed to render %v with format template: %v", desc["name"], err)
+		}
+		out.WriteString("\n")
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
@@ -0,0 +1,68 @@
+package ddevapp_test
+
+import (
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFilterAppDescs makes sure ddev list's filters, sorting and output
+// formats work on project descriptions.
+func TestFilterAppDescs(t *testing.T) {
+	assert := asrt.New(t)
+
+	descs := []map[string]interface{}{
+		{"name": "client-b", "type": "drupal8", "status": ddevapp.SiteRunning, "shortroot": "~/b", "httpurl": "http://client-b.ddev.site", "httpsurl": "https://client-b.ddev.site", "sync_status": ""},
+		{"name": "client-a", "type": "wordpress", "status": ddevapp.SiteStopped, "shortroot": "~/a", "sync_status": ""},
+		{"name": "internal", "type": "drupal8", "status": ddevapp.SitePaused, "shortroot": "~/internal", "sync_status": ""},
+	}
+	names := func(descs []map[string]interface{}) []string {
+		var names []string
+		for _, desc := range descs {
+			names = append(names, desc["name"].(string))
+		}
+		return names
+	}
+
+	filtered, err := ddevapp.FilterAppDescs(descs, ddevapp.ListOptions{})
+	require.NoError(t, err)
+	assert.Equal([]string{"client-b", "client-a", "internal"}, names(filtered))
+
+	filtered, err = ddevapp.FilterAppDescs(descs, ddevapp.ListOptions{NameGlob: "client-*", Sort: ddevapp.ListSortName})
+	require.NoError(t, err)
+	assert.Equal([]string{"client-a", "client-b"}, names(filtered))
+
+	filtered, err = ddevapp.FilterAppDescs(descs, ddevapp.ListOptions{Types: []string{"Drupal8"}, Sort: ddevapp.ListSortStatus})
+	require.NoError(t, err)
+	assert.Equal([]string{"internal", "client-b"}, names(filtered))
+
+	filtered, err = ddevapp.FilterAppDescs(descs, ddevapp.ListOptions{Statuses: []string{ddevapp.SiteRunning, ddevapp.SiteStopped}, Sort: ddevapp.ListSortType})
+	require.NoError(t, err)
+	assert.Equal([]string{"client-b", "client-a"}, names(filtered))
+
+	filtered, err = ddevapp.FilterAppDescs(descs, ddevapp.ListOptions{NameGlob: "nothing*"})
+	require.NoError(t, err)
+	assert.Empty(filtered)
+
+	_, err = ddevapp.FilterAppDescs(descs, ddevapp.ListOptions{NameGlob: "client-["})
+	assert.Error(err)
+	_, err = ddevapp.FilterAppDescs(descs, ddevapp.ListOptions{Sort: "age"})
+	assert.Error(err)
+	assert.Contains(err.Error(), "name, status, type")
+
+	rendered, err := ddevapp.RenderAppDescsTemplate(descs[:2], "{{ .name }}: {{ .type | upper }}")
+	require.NoError(t, err)
+	assert.Equal("client-b: DRUPAL8\nclient-a: WORDPRESS", rendered)
+	_, err = ddevapp.RenderAppDescsTemplate(descs, "{{ .name ")
+	assert.Error(err)
+
+	table := ddevapp.CreateAppTableWithColumns([]string{"name", "php_version", "location"})
+	for _, desc := range descs {
+		ddevapp.RenderAppRowColumns(table, desc, []string{"name", "php_version", "location"})
+	}
+	assert.Contains(table.String(), "PHP VERSION")
+	assert.Contains(table.String(), "~/internal")
+	assert.NotContains(table.String(), "STATUS")
+}
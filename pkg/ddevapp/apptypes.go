@@ -18,8 +18,9 @@ type hookDefaultComments func() []byte
 
 type apptypeSettingsPaths func(app *DdevApp)
 
-// appTypeDetect returns true if the app is of the specified type
-type appTypeDetect func(app *DdevApp) bool
+// appTypeDetect returns the files, relative to the project root, that show
+// the app is of the specified type, or nothing if it isn't.
+type appTypeDetect func(app *DdevApp) []string
 
 // postImportDBAction can take actions after import (like warning user about
 // required actions on Wordpress.
@@ -41,6 +42,14 @@ type importFilesAction func(app *DdevApp, importPath, extPath string) error
 // defaultWorkingDirMap returns the app type's default working directory map
 type defaultWorkingDirMap func(app *DdevApp, defaults map[string]string) map[string]string
 
+// settingsPermissionsAction makes the settings files writable before they're
+// created, instead of the default makeSettingsWritable.
+type settingsPermissionsAction func(app *DdevApp) error
+
+// settingsGitIgnoreAction keeps the ddev settings files out of git, instead
+// of the default settingsGitIgnore.
+type settingsGitIgnoreAction func(app *DdevApp) error
+
 // AppTypeFuncs struct defines the functions that can be called (if populated)
 // for a given appType.
 type AppTypeFuncs struct {
@@ -55,6 +64,8 @@ type AppTypeFuncs struct {
 	postStartAction
 	importFilesAction
 	defaultWorkingDirMap
+	settingsPermissionsAction
+	settingsGitIgnoreAction
 }
 
 // appTypeMatrix is a static map that defines the various functions to be called
@@ -65,22 +76,28 @@ func init() {
 	appTypeMatrix = map[string]AppTypeFuncs{
 		AppTypePHP: {},
 		AppTypeDrupal6: {
-			settingsCreator: createDrupal6SettingsFile, uploadDir: getDrupalUploadDir, hookDefaultComments: getDrupal6Hooks, apptypeSettingsPaths: setDrupalSiteSettingsPaths, appTypeDetect: isDrupal6App, postImportDBAction: nil, configOverrideAction: drupal6ConfigOverrideAction, postConfigAction: nil, postStartAction: drupal6PostStartAction, importFilesAction: drupalImportFilesAction, defaultWorkingDirMap: docrootWorkingDir,
+			settingsCreator: createDrupal6SettingsFile, uploadDir: getDrupalUploadDir, hookDefaultComments: getDrupal6Hooks, apptypeSettingsPaths: setDrupalSiteSettingsPaths, appTypeDetect: detectDrupal6App, postImportDBAction: nil, configOverrideAction: drupal6ConfigOverrideAction, postConfigAction: nil, postStartAction: drupal6PostStartAction, importFilesAction: drupalImportFilesAction, defaultWorkingDirMap: docrootWorkingDir,
 		},
 		AppTypeDrupal7: {
-			settingsCreator: createDrupal7SettingsFile, uploadDir: getDrupalUploadDir, hookDefaultComments: getDrupal7Hooks, apptypeSettingsPaths: setDrupalSiteSettingsPaths, appTypeDetect: isDrupal7App, postImportDBAction: nil, configOverrideAction: nil, postConfigAction: nil, postStartAction: drupal7PostStartAction, importFilesAction: drupalImportFilesAction, defaultWorkingDirMap: docrootWorkingDir,
+			settingsCreator: createDrupal7SettingsFile, uploadDir: getDrupalUploadDir, hookDefaultComments: getDrupal7Hooks, apptypeSettingsPaths: setDrupalSiteSettingsPaths, appTypeDetect: detectDrupal7App, postImportDBAction: nil, configOverrideAction: nil, postConfigAction: nil, postStartAction: drupal7PostStartAction, importFilesAction: drupalImportFilesAction, defaultWorkingDirMap: docrootWorkingDir,
 		},
 		AppTypeDrupal8: {
-			settingsCreator: createDrupal8SettingsFile, uploadDir: getDrupalUploadDir, hookDefaultComments: getDrupal8Hooks, apptypeSettingsPaths: setDrupalSiteSettingsPaths, appTypeDetect: isDrupal8App, postImportDBAction: nil, configOverrideAction: nil, postConfigAction: nil, postStartAction: drupal8PostStartAction, importFilesAction: drupalImportFilesAction, defaultWorkingDirMap: docrootWorkingDir,
+			settingsCreator: createDrupal8SettingsFile, uploadDir: getDrupalUploadDir, hookDefaultComments: getDrupal8Hooks, apptypeSettingsPaths: setDrupalSiteSettingsPaths, appTypeDetect: detectDrupal8App, postImportDBAction: nil, configOverrideAction: nil, postConfigAction: nil, postStartAction: drupal8PostStartAction, importFilesAction: drupalImportFilesAction, defaultWorkingDirMap: docrootWorkingDir,
 		},
 		AppTypeWordPress: {
-			settingsCreator: createWordpressSettingsFile, uploadDir: getWordpressUploadDir, hookDefaultComments: getWordpressHooks, apptypeSettingsPaths: setWordpressSiteSettingsPaths, appTypeDetect: isWordpressApp, postImportDBAction: nil, configOverrideAction: nil, postConfigAction: nil, postStartAction: wordpressPostStartAction, importFilesAction: wordpressImportFilesAction,
+			settingsCreator: createWordpressSettingsFile, uploadDir: getWordpressUploadDir, hookDefaultComments: getWordpressHooks, apptypeSettingsPaths: setWordpressSiteSettingsPaths, appTypeDetect: detectWordpressApp, postImportDBAction: nil, configOverrideAction: nil, postConfigAction: nil, postStartAction: wordpressPostStartAction, importFilesAction: wordpressImportFilesAction,
 		},
 		AppTypeTYPO3: {
-			settingsCreator: createTypo3SettingsFile, uploadDir: getTypo3UploadDir, hookDefaultComments: getTypo3Hooks, apptypeSettingsPaths: setTypo3SiteSettingsPaths, appTypeDetect: isTypo3App, postImportDBAction: nil, configOverrideAction: typo3ConfigOverrideAction, postConfigAction: nil, postStartAction: typo3PostStartAction, importFilesAction: typo3ImportFilesAction,
+			settingsCreator: createTypo3SettingsFile, uploadDir: getTypo3UploadDir, hookDefaultComments: getTypo3Hooks, apptypeSettingsPaths: setTypo3SiteSettingsPaths, appTypeDetect: detectTypo3App, postImportDBAction: nil, configOverrideAction: typo3ConfigOverrideAction, postConfigAction: nil, postStartAction: typo3PostStartAction, importFilesAction: typo3ImportFilesAction,
 		},
 		AppTypeBackdrop: {
-			settingsCreator: createBackdropSettingsFile, uploadDir: getBackdropUploadDir, hookDefaultComments: getBackdropHooks, apptypeSettingsPaths: setBackdropSiteSettingsPaths, appTypeDetect: isBackdropApp, postImportDBAction: backdropPostImportDBAction, configOverrideAction: nil, postConfigAction: nil, postStartAction: backdropPostStartAction, importFilesAction: backdropImportFilesAction, defaultWorkingDirMap: docrootWorkingDir,
+			settingsCreator: createBackdropSettingsFile, uploadDir: getBackdropUploadDir, hookDefaultComments: getBackdropHooks, apptypeSettingsPaths: setBackdropSiteSettingsPaths, appTypeDetect: detectBackdropApp, postImportDBAction: backdropPostImportDBAction, configOverrideAction: nil, postConfigAction: nil, postStartAction: backdropPostStartAction, importFilesAction: backdropImportFilesAction, defaultWorkingDirMap: docrootWorkingDir,
+		},
+		AppTypeLaravel: {
+			settingsCreator: createLaravelSettingsFile, uploadDir: getLaravelUploadDir, hookDefaultComments: getLaravelHooks, apptypeSettingsPaths: setLaravelSiteSettingsPaths, appTypeDetect: detectLaravelApp, postImportDBAction: nil, configOverrideAction: laravelConfigOverrideAction, postConfigAction: nil, postStartAction: laravelPostStartAction, importFilesAction: laravelImportFilesAction, settingsPermissionsAction: laravelSettingsPermissions, settingsGitIgnoreAction: laravelSettingsGitIgnore,
+		},
+		AppTypeMagento2: {
+			settingsCreator: createMagento2SettingsFile, uploadDir: getMagento2UploadDir, hookDefaultComments: getMagento2Hooks, apptypeSettingsPaths: setMagento2SiteSettingsPaths, appTypeDetect: detectMagento2App, postImportDBAction: magento2PostImportDBAction, configOverrideAction: magento2ConfigOverrideAction, postConfigAction: nil, postStartAction: magento2PostStartAction, importFilesAction: magento2ImportFilesAction,
 		},
 	}
 }
@@ -98,11 +115,51 @@ func (app *DdevApp) CreateSettingsFile() (string, error) {
 		return "", nil
 	}
 
-	// Drupal and WordPress love to change settings files to be unwriteable.
-	// Chmod them to something we can work with in the event that they already
-	// exist.
-	chmodTargets := []string{filepath.Dir(app.SiteSettingsPath), app.SiteDdevSettingsFile}
-	for _, fp := range chmodTargets {
+	if app.IsRouterDisabled() && app.GetHTTPURL() == "" {
+		util.Warning("The web container of %s has no published port yet, so its settings use %s, which needs the router. Set host_webserver_port and host_https_port for URLs that are known before the project starts.", app.Name, app.getRouterHTTPURL())
+	}
+
+	appFuncs, ok := app.getAppTypeFuncs()
+	permissionsAction := makeSettingsWritable
+	if ok && appFuncs.settingsPermissionsAction != nil {
+		permissionsAction = appFuncs.settingsPermissionsAction
+	}
+	if err := permissionsAction(app); err != nil {
+		return "", err
+	}
+
+	// If we have a function to do the settings creation, do it, otherwise
+	// just ignore.
+	if ok && appFuncs.settingsCreator != nil {
+		settingsPath, err := appFuncs.settingsCreator(app)
+		if err != nil {
+			util.Warning("Unable to create settings file: %v", err)
+		}
+		gitIgnoreAction := settingsGitIgnore
+		if appFuncs.settingsGitIgnoreAction != nil {
+			gitIgnoreAction = appFuncs.settingsGitIgnoreAction
+		}
+		if err = gitIgnoreAction(app); err != nil {
+			util.Warning("%v", err)
+		}
+		if app.Type == AppTypeDrupal8 {
+			drushDir := filepath.Join(filepath.Dir(app.SiteSettingsPath), "..", "all", "drush")
+			if err = CreateGitIgnore(drushDir, "drush.yml"); err != nil {
+				util.Warning("Failed to write .gitignore in %s: %v", drushDir, err)
+			}
+		}
+
+		return settingsPath, nil
+	}
+	return "", nil
+}
+
+// makeSettingsWritable makes the settings directory and the ddev settings
+// file writable. Drupal and WordPress love to change settings files to be
+// unwriteable, so chmod them to something we can work with in the event that
+// they already exist.
+func makeSettingsWritable(app *DdevApp) error {
+	for _, fp := range []string{filepath.Dir(app.SiteSettingsPath), app.SiteDdevSettingsFile} {
 		fileInfo, err := os.Stat(fp)
 		if err != nil {
 			// We're not doing anything about this error other than warning,
@@ -121,35 +178,24 @@ func (app *DdevApp) CreateSettingsFile() (string, error) {
 
 		err = os.Chmod(fp, os.FileMode(perms))
 		if err != nil {
-			return "", fmt.Errorf("could not change permissions on file %s to make it writeable: %v", fp, err)
+			return fmt.Errorf("could not change permissions on file %s to make it writeable: %v", fp, err)
 		}
 	}
+	return nil
+}
 
-	// If we have a function to do the settings creation, do it, otherwise
-	// just ignore.
-	if appFuncs, ok := appTypeMatrix[app.GetType()]; ok && appFuncs.settingsCreator != nil {
-		settingsPath, err := appFuncs.settingsCreator(app)
-		if err != nil {
-			util.Warning("Unable to create settings file: %v", err)
-		}
-		if err = CreateGitIgnore(filepath.Dir(app.SiteSettingsPath), filepath.Base(app.SiteDdevSettingsFile), "drushrc.php"); err != nil {
-			util.Warning("Failed to write .gitignore in %s: %v", filepath.Dir(app.SiteDdevSettingsFile), err)
-		}
-		if app.Type == AppTypeDrupal8 {
-			drushDir := filepath.Join(filepath.Dir(app.SiteSettingsPath), "..", "all", "drush")
-			if err = CreateGitIgnore(drushDir, "drush.yml"); err != nil {
-				util.Warning("Failed to write .gitignore in %s: %v", drushDir, err)
-			}
-		}
-
-		return settingsPath, nil
+// settingsGitIgnore writes a .gitignore for the ddev settings file (and
+// drushrc.php) next to the settings file.
+func settingsGitIgnore(app *DdevApp) error {
+	if err := CreateGitIgnore(filepath.Dir(app.SiteSettingsPath), filepath.Base(app.SiteDdevSettingsFile), "drushrc.php"); err != nil {
+		return fmt.Errorf("failed to write .gitignore in %s: %v", filepath.Dir(app.SiteDdevSettingsFile), err)
 	}
-	return "", nil
+	return nil
 }
 
 // GetUploadDir returns the upload (public files) directory for the given app
 func (app *DdevApp) GetUploadDir() string {
-	if appFuncs, ok := appTypeMatrix[app.GetType()]; ok && appFuncs.uploadDir != nil {
+	if appFuncs, ok := app.getAppTypeFuncs(); ok && appFuncs.uploadDir != nil {
 		uploadDir := appFuncs.uploadDir(app)
 		return uploadDir
 	}
@@ -159,7 +205,7 @@ func (app *DdevApp) GetUploadDir() string {
 // GetHookDefaultComments gets the actual text of the config.yaml hook suggestions
 // for a given apptype
 func (app *DdevApp) GetHookDefaultComments() []byte {
-	if appFuncs, ok := appTypeMatrix[app.Type]; ok && appFuncs.hookDefaultComments != nil {
+	if appFuncs, ok := app.getAppTypeFuncs(); ok && appFuncs.hookDefaultComments != nil {
 		suggestions := appFuncs.hookDefaultComments()
 		return suggestions
 	}
@@ -169,16 +215,46 @@ func (app *DdevApp) GetHookDefaultComments() []byte {
 // SetApptypeSettingsPaths chooses and sets the settings.php/settings.local.php
 // and related paths for a given app.
 func (app *DdevApp) SetApptypeSettingsPaths() {
-	if appFuncs, ok := appTypeMatrix[app.Type]; ok && appFuncs.apptypeSettingsPaths != nil {
+	if appFuncs, ok := app.getAppTypeFuncs(); ok && appFuncs.apptypeSettingsPaths != nil {
 		appFuncs.apptypeSettingsPaths(app)
 	}
 }
 
+// getAppTypeFuncs returns the functions of the app's type, which is either a
+// built-in type or a custom type defined in .ddev/apptypes.
+func (app *DdevApp) getAppTypeFuncs() (AppTypeFuncs, bool) {
+	if appFuncs, ok := appTypeMatrix[app.GetType()]; ok {
+		return appFuncs, true
+	}
+	if customType, ok := app.customAppTypes[app.GetType()]; ok {
+		return customType.appTypeFuncs(), true
+	}
+	return AppTypeFuncs{}, false
+}
+
+// IsValidProjectType returns true if apptype is a built-in project type or
+// one of the project's custom types.
+func (app *DdevApp) IsValidProjectType(apptype string) bool {
+	_, ok := app.customAppTypes[apptype]
+	return ok || IsValidAppType(apptype)
+}
+
+// GetValidProjectTypes returns the built-in project types plus the project's custom types.
+func (app *DdevApp) GetValidProjectTypes() []string {
+	return append(GetValidAppTypes(), app.GetCustomAppTypes()...)
+}
+
 // DetectAppType calls each apptype's detector until it finds a match,
-// or returns 'php' as a last resort.
+// or returns 'php' as a last resort. The project's custom types are tried
+// first, since they are more specific than the built-in ones.
 func (app *DdevApp) DetectAppType() string {
+	for _, name := range app.GetCustomAppTypes() {
+		if len(app.customAppTypes[name].detect(app)) > 0 {
+			return name
+		}
+	}
 	for appName, appFuncs := range appTypeMatrix {
-		if appFuncs.appTypeDetect != nil && appFuncs.appTypeDetect(app) {
+		if appFuncs.appTypeDetect != nil && len(appFuncs.appTypeDetect(app)) > 0 {
 			return appName
 		}
 	}
@@ -189,7 +265,7 @@ func (app *DdevApp) DetectAppType() string {
 // or returns 'php' as a last resort.
 func (app *DdevApp) PostImportDBAction() error {
 
-	if appFuncs, ok := appTypeMatrix[app.Type]; ok && appFuncs.postImportDBAction != nil {
+	if appFuncs, ok := app.getAppTypeFuncs(); ok && appFuncs.postImportDBAction != nil {
 		return appFuncs.postImportDBAction(app)
 	}
 
@@ -199,7 +275,7 @@ func (app *DdevApp) PostImportDBAction() error {
 // ConfigFileOverrideAction gives a chance for an apptype to override any element
 // of config.yaml that it needs to (on initial creation, but not after that)
 func (app *DdevApp) ConfigFileOverrideAction() error {
-	if appFuncs, ok := appTypeMatrix[app.Type]; ok && appFuncs.configOverrideAction != nil && !app.ConfigExists() {
+	if appFuncs, ok := app.getAppTypeFuncs(); ok && appFuncs.configOverrideAction != nil && !app.ConfigExists() {
 		return appFuncs.configOverrideAction(app)
 	}
 
@@ -209,7 +285,7 @@ func (app *DdevApp) ConfigFileOverrideAction() error {
 // PostConfigAction gives a chance for an apptype to override do something at
 // the end of ddev config.
 func (app *DdevApp) PostConfigAction() error {
-	if appFuncs, ok := appTypeMatrix[app.Type]; ok && appFuncs.postConfigAction != nil {
+	if appFuncs, ok := app.getAppTypeFuncs(); ok && appFuncs.postConfigAction != nil {
 		return appFuncs.postConfigAction(app)
 	}
 
@@ -219,7 +295,7 @@ func (app *DdevApp) PostConfigAction() error {
 // PostStartAction gives a chance for an apptype to do something after the app
 // has been started.
 func (app *DdevApp) PostStartAction() error {
-	if appFuncs, ok := appTypeMatrix[app.Type]; ok && appFuncs.postStartAction != nil {
+	if appFuncs, ok := app.getAppTypeFuncs(); ok && appFuncs.postStartAction != nil {
 		return appFuncs.postStartAction(app)
 	}
 
@@ -228,7 +304,7 @@ func (app *DdevApp) PostStartAction() error {
 
 // ImportFilesAction executes the relevant import files workflow for each app type.
 func (app *DdevApp) ImportFilesAction(importPath, extPath string) error {
-	if appFuncs, ok := appTypeMatrix[app.Type]; ok && appFuncs.importFilesAction != nil {
+	if appFuncs, ok := app.getAppTypeFuncs(); ok && appFuncs.importFilesAction != nil {
 		return appFuncs.importFilesAction(app, importPath, extPath)
 	}
 
@@ -245,7 +321,7 @@ func (app *DdevApp) DefaultWorkingDirMap() map[string]string {
 		"dba": "/home",
 	}
 
-	if appFuncs, ok := appTypeMatrix[app.Type]; ok && appFuncs.defaultWorkingDirMap != nil {
+	if appFuncs, ok := app.getAppTypeFuncs(); ok && appFuncs.defaultWorkingDirMap != nil {
 		return appFuncs.defaultWorkingDirMap(app, defaults)
 	}
 
@@ -7,6 +7,7 @@ import (
 
 	"bufio"
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/drud/ddev/pkg/ddevapp"
@@ -26,6 +27,8 @@ func TestApptypeDetection(t *testing.T) {
 		ddevapp.AppTypeDrupal8:   "core/scripts/drupal.sh",
 		ddevapp.AppTypeWordPress: "wp-settings.php",
 		ddevapp.AppTypeBackdrop:  "core/scripts/backdrop.sh",
+		ddevapp.AppTypeLaravel:   "artisan",
+		ddevapp.AppTypeMagento2:  "bin/magento",
 	}
 
 	for expectedType, expectedPath := range fileLocations {
@@ -99,3 +102,36 @@ func TestPostConfigAction(t *testing.T) {
 	}
 
 }
+
+// TestDetectionReport makes sure the detection report lists every matching
+// project type and docroot, and warns about a WordPress nested in a Drupal project.
+func TestDetectionReport(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	for _, f := range []string{"web/index.php", "web/core/scripts/drupal.sh", "web/blog/wp-settings.php"} {
+		err := os.MkdirAll(filepath.Join(testDir, filepath.Dir(f)), 0777)
+		assert.NoError(err)
+		err = ioutil.WriteFile(filepath.Join(testDir, f), []byte{}, 0644)
+		assert.NoError(err)
+	}
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	assert.NoError(err)
+
+	report := app.DetectionReport()
+	assert.Equal("web", report.Docroot)
+	assert.Contains([]string{ddevapp.AppTypeDrupal8, ddevapp.AppTypeWordPress}, report.AppType)
+	assert.Equal([]ddevapp.DocrootCandidate{{Docroot: "web", Evidence: []string{filepath.Join("web", "index.php")}}}, report.Docroots)
+	assert.Contains(report.Matches, ddevapp.DetectionMatch{AppType: ddevapp.AppTypeDrupal8, Docroot: "web", Evidence: []string{filepath.Join("web", "core", "scripts", "drupal.sh")}})
+	assert.Contains(report.Matches, ddevapp.DetectionMatch{AppType: ddevapp.AppTypeWordPress, Docroot: "web", Evidence: []string{filepath.Join("web", "blog", "wp-settings.php")}})
+	assert.Len(report.Matches, 2)
+	if assert.Len(report.Warnings, 1) {
+		assert.Contains(report.Warnings[0], "More than one project type matches (drupal8, wordpress)")
+	}
+	// The report doesn't change the project.
+	assert.Empty(app.Docroot)
+}
@@ -247,12 +247,9 @@ func setBackdropSiteSettingsPaths(app *DdevApp) {
 	app.SiteDdevSettingsFile = filepath.Join(settingsFileBasePath, settings.SiteSettingsDdev)
 }
 
-// isBackdropApp returns true if the app is of type "backdrop".
-func isBackdropApp(app *DdevApp) bool {
-	if _, err := os.Stat(filepath.Join(app.AppRoot, app.Docroot, "core/scripts/backdrop.sh")); err == nil {
-		return true
-	}
-	return false
+// detectBackdropApp returns the files showing the app is of type "backdrop".
+func detectBackdropApp(app *DdevApp) []string {
+	return app.existingFiles(app.Docroot, "core/scripts/backdrop.sh")
 }
 
 // backdropPostImportDBAction emits a warning about moving configuration into place
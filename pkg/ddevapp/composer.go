@@ -0,0 +1,145 @@
+package ddevapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/drud/ddev/pkg/fileutil"
+	"github.com/drud/ddev/pkg/util"
+)
+
+// composerVersionRegex matches the valid composer_version values: a major
+// version like "1" or "2", or a pinned version like "1.10.1" or "2.0.0-RC1".
+var composerVersionRegex = regexp.MustCompile(`^([12]|[12]\.[0-9]+\.[0-9]+(-(alpha|beta|RC)[0-9]*)?)$`)
+
+// IsValidComposerVersion returns true if version is a valid composer_version,
+// or empty to use the composer the web image comes with.
+func IsValidComposerVersion(version string) bool {
+	return version == "" || composerVersionRegex.MatchString(version)
+}
+
+// composerDockerfileRun returns the Dockerfile instruction that replaces the
+// web image's composer with the latest of a major version or a pinned version.
+func composerDockerfileRun(version string) string {
+	versionArg := "--" + version
+	if strings.Contains(version, ".") {
+		versionArg = "--version=" + version
+	}
+	return "RUN curl -sSL https://getcomposer.org/installer | php -- --install-dir=/usr/local/bin --filename=composer " + versionArg
+}
+
+// composerJSON is the part of composer.json the PHP version check reads.
+type composerJSON struct {
+	Require map[string]string `json:"require"`
+	Config  struct {
+		Platform map[string]string `json:"platform"`
+	} `json:"config"`
+}
+
+// composerLock is the part of composer.lock the PHP version check reads.
+type composerLock struct {
+	Packages          []composerLockPackage `json:"packages"`
+	PackagesDev       []composerLockPackage `json:"packages-dev"`
+	PlatformOverrides map[string]string     `json:"platform-overrides"`
+}
+
+// composerLockPackage is a package locked in composer.lock.
+type composerLockPackage struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	Require map[string]string `json:"require"`
+}
+
+// ComposerPHPDrift compares the PHP requirements of the project's
+// composer.json and composer.lock with the configured php_version, and
+// returns a description of each mismatch.
+func (app *DdevApp) ComposerPHPDrift() ([]string, error) {
+	var drift []string
+	phpVersion := app.GetPhpVersion()
+	// composer compares the full version, so assume the latest patch release.
+	version, err := semver.NewVersion(phpVersion + ".99")
+	if err != nil {
+		return nil, err
+	}
+
+	jsonFile := filepath.Join(app.AppRoot, "composer.json")
+	if fileutil.FileExists(jsonFile) {
+		contents, err := ioutil.ReadFile(jsonFile)
+		if err != nil {
+			return nil, err
+		}
+		composer := composerJSON{}
+		if err = json.Unmarshal(contents, &composer); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %v", jsonFile, err)
+		}
+		if platform := composer.Config.Platform["php"]; platform != "" && !samePHPMinor(platform, phpVersion) {
+			drift = append(drift, fmt.Sprintf("composer.json config.platform.php is %s, but php_version is %s", platform, phpVersion))
+		}
+		if constraint := composer.Require["php"]; constraint != "" && !composerConstraintAllows(constraint, version) {
+			drift = append(drift, fmt.Sprintf("composer.json requires php %s, but php_version is %s", constraint, phpVersion))
+		}
+	}
+
+	lockFile := filepath.Join(app.AppRoot, "composer.lock")
+	if fileutil.FileExists(lockFile) {
+		contents, err := ioutil.ReadFile(lockFile)
+		if err != nil {
+			return nil, err
+		}
+		lock := composerLock{}
+		if err = json.Unmarshal(contents, &lock); err != nil {
+			return nil, fmt.Errorf("unable to parse %s: %v", lockFile, err)
+		}
+		if platform := lock.PlatformOverrides["php"]; platform != "" && !samePHPMinor(platform, phpVersion) {
+			drift = append(drift, fmt.Sprintf("composer.lock was resolved for platform php %s, but php_version is %s", platform, phpVersion))
+		}
+		var packages []string
+		for _, p := range append(lock.Packages, lock.PackagesDev...) {
+			if constraint := p.Require["php"]; constraint != "" && !composerConstraintAllows(constraint, version) {
+				packages = append(packages, fmt.Sprintf("%s %s (php %s)", p.Name, p.Version, constraint))
+			}
+		}
+		if len(packages) > 0 {
+			sort.Strings(packages)
+			drift = append(drift, fmt.Sprintf("composer.lock has packages that don't support php_version %s: %s", phpVersion, strings.Join(packages, ", ")))
+		}
+	}
+
+	return drift, nil
+}
+
+// WarnComposerPHPDrift warns about each mismatch found by ComposerPHPDrift.
+func (app *DdevApp) WarnComposerPHPDrift() {
+	drift, err := app.ComposerPHPDrift()
+	if err != nil {
+		util.Warning("Unable to check composer PHP requirements: %v", err)
+		return
+	}
+	for _, d := range drift {
+		util.Warning("%s", d)
+	}
+}
+
+// samePHPMinor returns true if the PHP version a (like "7.2.5") has the same
+// major and minor version as b (like "7.2").
+func samePHPMinor(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".")
+}
+
+// composerConstraintAllows returns true if the composer version constraint
+// allows version. Constraints it doesn't understand are assumed to allow it.
+func composerConstraintAllows(constraint string, version *semver.Version) bool {
+	// composer accepts "|" as well as "||" for "or".
+	constraint = strings.Replace(strings.Replace(constraint, "||", "|", -1), "|", "||", -1)
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return true
+	}
+	return c.Check(version)
+}
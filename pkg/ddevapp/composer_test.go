@@ -0,0 +1,89 @@
+package ddevapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/testcommon"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestComposerVersion makes sure composer_version is validated, installs the
+// chosen composer in the web image.
+func TestComposerVersion(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	app.Name = "composerversion"
+
+	for _, v := range []string{"3", "1.x", "latest", "1.10"} {
+		app.ComposerVersion = v
+		err = app.ValidateConfig()
+		assert.Error(err, "composer_version %s", v)
+	}
+
+	for v, expected := range map[string]string{"2": "--filename=composer --2", "1.10.1": "--version=1.10.1", "2.0.0-RC1": "--version=2.0.0-RC1"} {
+		app.ComposerVersion = v
+		assert.NoError(app.ValidateConfig())
+		_, err := app.RenderComposeYAML()
+		require.NoError(t, err)
+		dockerfile, err := ioutil.ReadFile(filepath.Join(app.GetConfigPath(".webimageExtra"), "Dockerfile"))
+		require.NoError(t, err)
+		assert.Contains(string(dockerfile), expected)
+	}
+}
+
+// TestComposerPHPDrift makes sure mismatches between composer.json,
+// composer.lock and php_version are reported.
+func TestComposerPHPDrift(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	app.PHPVersion = ddevapp.PHP72
+
+	drift, err := app.ComposerPHPDrift()
+	assert.NoError(err)
+	assert.Empty(drift)
+
+	err = ioutil.WriteFile(filepath.Join(testDir, "composer.json"), []byte(`{
+  "require": {"php": "^7.1.3"},
+  "config": {"platform": {"php": "7.2.5"}}
+}`), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(testDir, "composer.lock"), []byte(`{
+  "packages": [
+    {"name": "vendor/ok", "version": "1.0.0", "require": {"php": ">=5.6 || ~7.0"}},
+    {"name": "vendor/new", "version": "2.0.0", "require": {"php": "^7.3"}}
+  ],
+  "packages-dev": [
+    {"name": "vendor/old", "version": "0.1.0", "require": {"php": "~5.6|~7.0.0"}}
+  ],
+  "platform-overrides": {"php": "7.2.5"}
+}`), 0644)
+	require.NoError(t, err)
+
+	drift, err = app.ComposerPHPDrift()
+	assert.NoError(err)
+	if assert.Len(drift, 1) {
+		assert.Contains(drift[0], "vendor/new 2.0.0 (php ^7.3), vendor/old 0.1.0 (php ~5.6|~7.0.0)")
+	}
+
+	app.PHPVersion = ddevapp.PHP71
+	drift, err = app.ComposerPHPDrift()
+	assert.NoError(err)
+	assert.Contains(drift, "composer.json config.platform.php is 7.2.5, but php_version is 7.1")
+	assert.Contains(drift, "composer.lock was resolved for platform php 7.2.5, but php_version is 7.1")
+}
@@ -36,6 +36,12 @@ var hostRegex = regexp.MustCompile(`^(([a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9\-]*[a-z
 type Command struct {
 	Exec     string `yaml:"exec,omitempty"`
 	ExecHost string `yaml:"exec-host,omitempty"`
+	Composer string `yaml:"composer,omitempty"`
+	Drush    string `yaml:"drush,omitempty"`
+	// Service is the service an exec task runs in, web by default.
+	Service string `yaml:"service,omitempty"`
+	// ContinueOnError makes a failing task a warning instead of an error.
+	ContinueOnError bool `yaml:"continue_on_error,omitempty"`
 }
 
 // Provider is the interface which all provider plugins must implement.
@@ -80,28 +86,7 @@ func NewApp(AppRoot string, includeOverrides bool, provider string) (*DdevApp, e
 		return app, fmt.Errorf("project root %s does not exist", AppRoot)
 	}
 	app.ConfigPath = app.GetConfigPath("config.yaml")
-	app.APIVersion = version.DdevVersion
-	app.Type = AppTypePHP
-	app.PHPVersion = PHPDefault
-	app.WebserverType = WebserverDefault
-	app.WebcacheEnabled = WebcacheEnabledDefault
-	app.NFSMountEnabled = NFSMountEnabledDefault
-	app.RouterHTTPPort = DdevDefaultRouterHTTPPort
-	app.RouterHTTPSPort = DdevDefaultRouterHTTPSPort
-	app.PHPMyAdminPort = DdevDefaultPHPMyAdminPort
-	app.MailhogPort = DdevDefaultMailhogPort
-	app.MariaDBVersion = version.MariaDBDefaultVersion
-	// Provide a default app name based on directory name
-	app.Name = filepath.Base(app.AppRoot)
-	app.OmitContainers = globalconfig.DdevGlobalConfig.OmitContainers
-	app.ProjectTLD = DdevDefaultTLD
-	app.UseDNSWhenPossible = true
-
-	// These should always default to the latest image/tag names from the Version package.
-	app.WebImage = version.GetWebImage()
-	app.DBImage = version.GetDBImage(version.MariaDBDefaultVersion)
-	app.DBAImage = version.GetDBAImage()
-	app.BgsyncImage = version.GetBgsyncImage()
+	app.setDefaults()
 
 	// Load from file if available. This will return an error if the file doesn't exist,
 	// and it is up to the caller to determine if that's an issue.
@@ -111,6 +96,12 @@ func NewApp(AppRoot string, includeOverrides bool, provider string) (*DdevApp, e
 			return app, fmt.Errorf("%v exists but cannot be read. It may be invalid due to a syntax error.: %v", app.ConfigPath, err)
 		}
 	}
+
+	customAppTypes, err := ReadCustomAppTypes(app.GetConfigPath(CustomAppTypesDir))
+	if err != nil {
+		return app, err
+	}
+	app.customAppTypes = customAppTypes
 	app.SetApptypeSettingsPaths()
 
 	// If the dbimage has not been overridden (because it takes precedence
@@ -141,6 +132,33 @@ func NewApp(AppRoot string, includeOverrides bool, provider string) (*DdevApp, e
 	return app, nil
 }
 
+// setDefaults sets the values the config of a project has before the config
+// files are read.
+func (app *DdevApp) setDefaults() {
+	app.APIVersion = version.DdevVersion
+	app.Type = AppTypePHP
+	app.PHPVersion = PHPDefault
+	app.WebserverType = WebserverDefault
+	app.WebcacheEnabled = WebcacheEnabledDefault
+	app.NFSMountEnabled = NFSMountEnabledDefault
+	app.RouterHTTPPort = DdevDefaultRouterHTTPPort
+	app.RouterHTTPSPort = DdevDefaultRouterHTTPSPort
+	app.PHPMyAdminPort = DdevDefaultPHPMyAdminPort
+	app.MailhogPort = DdevDefaultMailhogPort
+	app.MariaDBVersion = version.MariaDBDefaultVersion
+	// Provide a default app name based on directory name
+	app.Name = filepath.Base(app.AppRoot)
+	app.OmitContainers = globalconfig.DdevGlobalConfig.OmitContainers
+	app.ProjectTLD = DdevDefaultTLD
+	app.UseDNSWhenPossible = true
+
+	// These should always default to the latest image/tag names from the Version package.
+	app.WebImage = version.GetWebImage()
+	app.DBImage = version.GetDBImage(version.MariaDBDefaultVersion)
+	app.DBAImage = version.GetDBAImage()
+	app.BgsyncImage = version.GetBgsyncImage()
+}
+
 // GetConfigPath returns the path to an application config file specified by filename.
 func (app *DdevApp) GetConfigPath(filename string) string {
 	return filepath.Join(app.AppRoot, ".ddev", filename)
@@ -206,6 +224,15 @@ func (app *DdevApp) WriteConfig() error {
 		return err
 	}
 
+	// Keep the environment variable references of values that didn't change,
+	// instead of writing the values of this environment.
+	if source, err := ioutil.ReadFile(appcopy.ConfigPath); err == nil && configUsesEnv(source) {
+		cfgbytes, err = keepConfigEnvReferences(source, cfgbytes)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Append current image information
 	cfgbytes = append(cfgbytes, []byte(fmt.Sprintf("\n\n# This config.yaml was created with ddev version %s \n# webimage: %s\n# dbimage: %s\n# dbaimage: %s\n# bgsyncimage: %s\n# However we do not recommend explicitly wiring these images into the\n# config.yaml as they may break future versions of ddev.\n# You can update this config.yaml using 'ddev config'.\n", version.DdevVersion, version.GetWebImage(), version.GetDBImage(), version.GetDBAImage(), version.GetBgsyncImage()))...)
 
@@ -315,8 +342,7 @@ func (app *DdevApp) ReadConfig(includeOverrides bool) ([]string, error) {
 	configOverrides := []string{}
 	// Load config.*.y*ml after in glob order
 	if includeOverrides {
-		glob := filepath.Join(filepath.Dir(app.ConfigPath), "config.*.y*ml")
-		configOverrides, err = filepath.Glob(glob)
+		configOverrides, err = app.configOverrideFiles()
 		if err != nil {
 			return []string{}, err
 		}
@@ -345,6 +371,14 @@ func (app *DdevApp) LoadConfigYamlFile(filePath string) error {
 		return fmt.Errorf("invalid configuration in %s: %v", app.ConfigPath, err)
 	}
 
+	source, unset, err := interpolateConfigEnv(source)
+	if err != nil {
+		return err
+	}
+	for _, name := range unset {
+		util.Warning("The %s variable used in %s is not set, using an empty string", name, filePath)
+	}
+
 	// ReadConfig config values from file.
 	err = yaml.Unmarshal(source, app)
 	if err != nil {
@@ -417,7 +451,7 @@ func (app *DdevApp) ValidateConfig() error {
 	}
 
 	// validate apptype
-	if !IsValidAppType(app.Type) {
+	if !app.IsValidProjectType(app.Type) {
 		return fmt.Errorf("invalid app type: %s", app.Type).(invalidAppType)
 	}
 
@@ -440,6 +474,30 @@ func (app *DdevApp) ValidateConfig() error {
 		return fmt.Errorf("invalid mariadb_version: %s, must be one of %s", app.MariaDBVersion, GetValidMariaDBVersions()).(invalidMariaDBVersion)
 	}
 
+	if err = app.validateDrupalMultisites(); err != nil {
+		return err
+	}
+
+	if !IsValidProfiler(app.Profiler) {
+		return fmt.Errorf("invalid profiler: %s, must be one of %s", app.Profiler, GetValidProfilers()).(invalidProfiler)
+	}
+
+	if !IsValidNodeJSVersion(app.NodeJSVersion) {
+		return fmt.Errorf("invalid nodejs_version: %s, must be one of %s", app.NodeJSVersion, GetValidNodeJSVersions()).(invalidNodeJSVersion)
+	}
+
+	if !IsValidComposerVersion(app.ComposerVersion) {
+		return fmt.Errorf("invalid composer_version: %s, must be 1, 2 or a version like 1.10.1", app.ComposerVersion).(invalidComposerVersion)
+	}
+
+	if err = app.validatePHPExtensions(); err != nil {
+		return err
+	}
+
+	if err = app.validateEnvironment(); err != nil {
+		return err
+	}
+
 	if app.WebcacheEnabled && app.NFSMountEnabled {
 		return fmt.Errorf("webcache_enabled and nfs_mount_enabled cannot both be set to true, use one or the other")
 	}
@@ -475,6 +533,11 @@ func (app *DdevApp) GetHostnames() []string {
 		nameListMap[name] = 1
 	}
 
+	// Each Drupal multisite is reached through its own additional hostname.
+	for _, site := range app.DrupalMultisites {
+		nameListMap[site.GetHostname()+"."+app.ProjectTLD] = 1
+	}
+
 	// Now walk the map and extract the keys into an array.
 	nameListArray := make([]string, 0, len(nameListMap))
 	for k := range nameListMap {
@@ -564,6 +627,16 @@ func (app *DdevApp) CheckCustomConfig() {
 			customConfig = true
 		}
 	}
+
+	if certs, err := app.GetCustomCerts(); err == nil && len(certs) > 0 {
+		var hostnames []string
+		for hostname := range certs {
+			hostnames = append(hostnames, hostname)
+		}
+		sort.Strings(hostnames)
+		util.Warning("Using custom certificates in %s for %v", filepath.Join(ddevDir, CustomCertsDir), hostnames)
+		customConfig = true
+	}
 	if customConfig {
 		util.Warning("Custom configuration takes effect when container is created, \nusually on start, use 'ddev restart' if you're not seeing it take effect.")
 	}
@@ -586,12 +659,17 @@ type composeYAMLVars struct {
 	DBBuildContext       string
 	OmitDBA              bool
 	OmitSSHAgent         bool
+	OmitRouter           bool
+	Profiler             string
+	BlackfireImage       string
 	WebcacheEnabled      bool
 	NFSMountEnabled      bool
 	NFSSource            string
 	DockerIP             string
 	IsWindowsFS          bool
 	Hostnames            []string
+	WebEnvironment       []template.HTML
+	DBEnvironment        []template.HTML
 }
 
 // RenderComposeYAML renders the contents of docker-compose.yaml.
@@ -627,6 +705,9 @@ func (app *DdevApp) RenderComposeYAML() (string, error) {
 		ComposeVersion:       version.DockerComposeFileFormatVersion,
 		OmitDBA:              nodeps.ArrayContainsString(app.OmitContainers, "dba"),
 		OmitSSHAgent:         nodeps.ArrayContainsString(app.OmitContainers, "ddev-ssh-agent"),
+		OmitRouter:           app.IsRouterDisabled(),
+		Profiler:             app.Profiler,
+		BlackfireImage:       version.BlackfireImg + ":" + version.BlackfireTag,
 		WebcacheEnabled:      app.WebcacheEnabled,
 		NFSMountEnabled:      app.NFSMountEnabled,
 		NFSSource:            "",
@@ -639,6 +720,17 @@ func (app *DdevApp) RenderComposeYAML() (string, error) {
 		templateVars.MountType = "volume"
 		templateVars.WebMount = "webcachevol"
 	}
+
+	webEnvironment, err := app.GetWebEnvironment()
+	if err != nil {
+		return "", err
+	}
+	templateVars.WebEnvironment = composeEnvironment(webEnvironment)
+	dbEnvironment, err := app.GetDBEnvironment()
+	if err != nil {
+		return "", err
+	}
+	templateVars.DBEnvironment = composeEnvironment(dbEnvironment)
 	if app.NFSMountEnabled {
 		templateVars.MountType = "volume"
 		templateVars.WebMount = "nfsmount"
@@ -650,14 +742,31 @@ func (app *DdevApp) RenderComposeYAML() (string, error) {
 		}
 	}
 
+	err = app.writeProfilerConfig()
+	if err != nil {
+		return "", err
+	}
+
 	webBuildContext := app.GetConfigPath("web-build/Dockerfile")
 	if fileutil.FileExists(webBuildContext) {
 		templateVars.WebBuildContext = app.GetConfigPath("web-build")
 		if len(app.WebImageExtraPackages) != 0 {
 			util.Warning(".ddev/web-build/Dockerfile is provided, ignoring webimage_extra_packages")
 		}
-	} else if len(app.WebImageExtraPackages) > 0 {
-		err = WriteImagePackagesDockerfile(app.GetConfigPath(".webimageExtra/Dockerfile"), app.WebImageExtraPackages)
+		if app.Profiler == ProfilerBlackfire {
+			util.Warning(".ddev/web-build/Dockerfile is provided, so it must install the blackfire probe itself")
+		}
+		if app.NodeJSVersion != "" {
+			util.Warning(".ddev/web-build/Dockerfile is provided, ignoring nodejs_version")
+		}
+		if app.ComposerVersion != "" {
+			util.Warning(".ddev/web-build/Dockerfile is provided, ignoring composer_version")
+		}
+		if len(app.PHPExtensions) != 0 {
+			util.Warning(".ddev/web-build/Dockerfile is provided, ignoring php_extensions")
+		}
+	} else if runs := app.webImageExtraRuns(); len(runs) > 0 {
+		err = WriteImageDockerfile(app.GetConfigPath(".webimageExtra/Dockerfile"), []byte("ARG BASE_IMAGE\nFROM $BASE_IMAGE\n"+strings.Join(runs, "\n")+"\n"))
 		if err != nil {
 			return "", err
 		}
@@ -687,6 +796,29 @@ func (app *DdevApp) RenderComposeYAML() (string, error) {
 	return doc.String(), err
 }
 
+// webImageExtraRuns returns the RUN instructions of the generated web image
+// Dockerfile, or nothing if the stock web image can be used as is.
+func (app *DdevApp) webImageExtraRuns() []string {
+	var runs []string
+	if len(app.WebImageExtraPackages) > 0 {
+		runs = append(runs, "RUN apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y "+strings.Join(app.WebImageExtraPackages, " "))
+	}
+	// After the extra packages, which may provide libraries the extensions need.
+	if len(app.PHPExtensions) > 0 {
+		runs = append(runs, phpExtensionsDockerfileRun(app.GetPhpVersion(), app.PHPExtensions))
+	}
+	if app.Profiler == ProfilerBlackfire {
+		runs = append(runs, blackfireDockerfileRun(app.GetPhpVersion()))
+	}
+	if app.NodeJSVersion != "" {
+		runs = append(runs, nodejsDockerfileRun(app.NodeJSVersion))
+	}
+	if app.ComposerVersion != "" {
+		runs = append(runs, composerDockerfileRun(app.ComposerVersion))
+	}
+	return runs
+}
+
 // WriteImagePackagesDockerfile writes a simple Dockerfile with extraPackages at given location
 // fullpath is the path to the Dockerfile including the filename
 func WriteImagePackagesDockerfile(fullpath string, extraPackages []string) error {
@@ -825,7 +957,7 @@ func (app *DdevApp) AppTypePrompt() error {
 	if err != nil {
 		return err
 	}
-	validAppTypes := strings.Join(GetValidAppTypes(), ", ")
+	validAppTypes := strings.Join(app.GetValidProjectTypes(), ", ")
 	typePrompt := fmt.Sprintf("Project Type [%s]", validAppTypes)
 
 	// First, see if we can auto detect what kind of site it is so we can set a sane default.
@@ -840,7 +972,7 @@ func (app *DdevApp) AppTypePrompt() error {
 	fmt.Printf(typePrompt + ": ")
 	appType := strings.ToLower(util.GetInput(detectedAppType))
 
-	for !IsValidAppType(appType) {
+	for !app.IsValidProjectType(appType) {
 		output.UserOut.Errorf("'%s' is not a valid project type. Allowed project types are: %s\n", appType, validAppTypes)
 
 		fmt.Printf(typePrompt + ": ")
@@ -864,7 +996,7 @@ func PrepDdevDirectory(dir string) error {
 		}
 	}
 
-	err := CreateGitIgnore(dir, "import.yaml", "docker-compose.yaml", "db_snapshots", "sequelpro.spf", "import-db", ".bgsync*", "config.*.y*ml", ".webimageExtra", ".dbimageExtra", "*-build/Dockerfile.example")
+	err := CreateGitIgnore(dir, "import.yaml", "docker-compose.yaml", "db_snapshots", "sequelpro.spf", "import-db", ".bgsync*", "config.*.y*ml", ".webimageExtra", ".dbimageExtra", "*-build/Dockerfile.example", ".profiler", ProfilesDir)
 	if err != nil {
 		return fmt.Errorf("failed to create gitignore in %s: %v", dir, err)
 	}
@@ -877,15 +1009,32 @@ func validateCommandYaml(source []byte) error {
 	validHooks := []string{
 		"pre-start",
 		"post-start",
+		"pre-stop",
 		"pre-import-db",
 		"post-import-db",
 		"pre-import-files",
 		"post-import-files",
+		"pre-export-db",
+		"post-export-db",
+		"pre-snapshot",
+		"post-snapshot",
+		"pre-restore-snapshot",
+		"post-restore-snapshot",
+		"pre-pull",
+		"post-pull",
 	}
 
 	validTasks := []string{
 		"exec",
 		"exec-host",
+		"composer",
+		"drush",
+	}
+
+	// Options change how a task runs, but aren't tasks themselves.
+	validOptions := []string{
+		"service",
+		"continue_on_error",
 	}
 
 	type Validate struct {
@@ -910,16 +1059,21 @@ func validateCommandYaml(source []byte) error {
 		}
 
 		for _, taskSet := range tasks {
+			var taskNames []string
 			for taskName := range taskSet {
-				var match bool
-				for _, validTask := range validTasks {
-					if taskName == validTask {
-						match = true
-					}
+				if nodeps.ArrayContainsString(validOptions, taskName) {
+					continue
 				}
-				if !match {
+				if !nodeps.ArrayContainsString(validTasks, taskName) {
 					return fmt.Errorf("invalid task '%s' defined for %s hook in config.yaml", taskName, command)
 				}
+				taskNames = append(taskNames, taskName)
+			}
+			if len(taskNames) != 1 {
+				return fmt.Errorf("each task for %s hook in config.yaml needs exactly one of %s", command, strings.Join(validTasks, ", "))
+			}
+			if _, ok := taskSet["service"]; ok && taskNames[0] != "exec" {
+				return fmt.Errorf("service can only be used with exec, not with %s, in %s hook in config.yaml", taskNames[0], command)
 			}
 		}
 
@@ -15,6 +15,7 @@ import (
 
 	. "github.com/drud/ddev/pkg/ddevapp"
 	"github.com/drud/ddev/pkg/fileutil"
+	"github.com/drud/ddev/pkg/globalconfig"
 	"github.com/drud/ddev/pkg/testcommon"
 	"github.com/drud/ddev/pkg/util"
 	"github.com/drud/ddev/pkg/version"
@@ -944,3 +945,219 @@ func TestPkgConfigMariaDBVersion(t *testing.T) {
 	}
 
 }
+
+// TestConfigHooks tests that hooks in config.yaml are read and that invalid
+// hooks and task combinations are rejected.
+func TestConfigHooks(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	err := os.MkdirAll(filepath.Join(testDir, ".ddev"), 0755)
+	require.NoError(t, err)
+	configFile := filepath.Join(testDir, ".ddev", "config.yaml")
+
+	err = ioutil.WriteFile(configFile, []byte(`name: hooks
+type: php
+hooks:
+  pre-snapshot:
+    - exec: mysql -e "FLUSH TABLES"
+      service: db
+  post-pull:
+    - composer: install
+    - drush: cr
+      continue_on_error: true
+  pre-stop:
+    - exec-host: echo stopping
+`), 0644)
+	require.NoError(t, err)
+	app, err := NewApp(testDir, true, ProviderDefault)
+	require.NoError(t, err)
+	assert.Equal([]Command{{Exec: `mysql -e "FLUSH TABLES"`, Service: "db"}}, app.Commands["pre-snapshot"])
+	assert.Equal([]Command{{Composer: "install"}, {Drush: "cr", ContinueOnError: true}}, app.Commands["post-pull"])
+	assert.Equal([]Command{{ExecHost: "echo stopping"}}, app.Commands["pre-stop"])
+
+	for hooks, expected := range map[string]string{
+		"post-potato:\n    - exec: ls\n":                            "invalid command hook",
+		"post-start:\n    - potato: ls\n":                           "invalid task 'potato'",
+		"post-start:\n    - exec: ls\n      composer: install\n":    "exactly one of",
+		"post-start:\n    - continue_on_error: true\n":              "exactly one of",
+		"post-start:\n    - composer: install\n      service: db\n": "service can only be used with exec",
+	} {
+		err = ioutil.WriteFile(configFile, []byte("name: hooks\ntype: php\nhooks:\n  "+hooks), 0644)
+		require.NoError(t, err)
+		_, err = NewApp(testDir, true, ProviderDefault)
+		if assert.Error(err, hooks) {
+			assert.Contains(err.Error(), expected)
+		}
+	}
+}
+
+// TestConfigEnvInterpolation tests that environment variables are replaced
+// in config.yaml and config.*.yaml, but not in hooks.
+func TestConfigEnvInterpolation(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	err := os.MkdirAll(filepath.Join(testDir, ".ddev"), 0755)
+	require.NoError(t, err)
+
+	for k, v := range map[string]string{"DDEV_TEST_HTTP_PORT": "8088", "DDEV_TEST_EMPTY": "", "DDEV_TEST_HOST": "extra"} {
+		require.NoError(t, os.Setenv(k, v))
+		// nolint: errcheck
+		defer os.Unsetenv(k)
+	}
+	require.NoError(t, os.Unsetenv("DDEV_TEST_UNSET"))
+
+	err = ioutil.WriteFile(filepath.Join(testDir, ".ddev", "config.yaml"), []byte(`name: interpolation
+type: php
+# ${DDEV_TEST_UNSET} in a comment is left alone
+router_http_port: ${DDEV_TEST_HTTP_PORT}
+router_https_port: "${DDEV_TEST_UNSET:-8443}"
+php_version: ${DDEV_TEST_EMPTY:-7.3}
+webserver_type: ${DDEV_TEST_EMPTY-apache-fpm}
+upload_dir: files$${DDEV_TEST_HOST}
+hooks:
+  post-start:
+    - exec: echo ${DDEV_TEST_HOST}
+`), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(testDir, ".ddev", "config.local.yaml"), []byte("additional_hostnames:\n  - ${DDEV_TEST_HOST}-site\n"), 0644)
+	require.NoError(t, err)
+
+	app, err := NewApp(testDir, true, ProviderDefault)
+	require.NoError(t, err)
+	assert.Equal("8088", app.RouterHTTPPort)
+	assert.Equal("8443", app.RouterHTTPSPort)
+	assert.Equal("7.3", app.PHPVersion)
+	assert.Equal("", app.WebserverType)
+	assert.Equal("files${DDEV_TEST_HOST}", app.UploadDir)
+	assert.Equal([]string{"extra-site"}, app.AdditionalHostnames)
+	assert.Equal("echo ${DDEV_TEST_HOST}", app.Commands["post-start"][0].Exec)
+
+	// Values are only replaced in parsed values, so YAML syntax in them, even
+	// a newline followed by a key, stays part of the value.
+	tricky := "8080\nomit_containers: [db]"
+	for k, v := range map[string]string{"DDEV_TEST_TRICKY": tricky, "DDEV_TEST_COLON": "a: b # c", "DDEV_TEST_BOOL": "true"} {
+		require.NoError(t, os.Setenv(k, v))
+		// nolint: errcheck
+		defer os.Unsetenv(k)
+	}
+	err = os.Remove(filepath.Join(testDir, ".ddev", "config.local.yaml"))
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(testDir, ".ddev", "config.yaml"), []byte(`name: interpolation
+type: php
+upload_dir: ${DDEV_TEST_TRICKY}
+web_environment:
+  - SOMETHING=${DDEV_TEST_COLON}
+nfs_mount_enabled: ${DDEV_TEST_BOOL}
+`), 0644)
+	require.NoError(t, err)
+
+	app, err = NewApp(testDir, true, ProviderDefault)
+	require.NoError(t, err)
+	assert.Equal(tricky, app.UploadDir)
+	assert.Empty(app.OmitContainers)
+	assert.Equal([]string{"SOMETHING=a: b # c"}, app.WebEnvironment)
+	assert.True(app.NFSMountEnabled)
+	problems, err := app.ValidateConfigSchema(true)
+	require.NoError(t, err)
+	assert.Empty(problems)
+
+	// Writing the config keeps the references of the values that didn't change.
+	app.PHPVersion = PHP73
+	err = app.WriteConfig()
+	require.NoError(t, err)
+	written, err := ioutil.ReadFile(app.ConfigPath)
+	require.NoError(t, err)
+	assert.Contains(string(written), "upload_dir: ${DDEV_TEST_TRICKY}")
+	assert.Contains(string(written), "- SOMETHING=${DDEV_TEST_COLON}")
+	assert.Contains(string(written), "nfs_mount_enabled: ${DDEV_TEST_BOOL}")
+	assert.Contains(string(written), "php_version: \"7.3\"")
+	assert.NotContains(string(written), "\nomit_containers:")
+
+	// A reference that gives a value of the wrong type is reported.
+	require.NoError(t, os.Setenv("DDEV_TEST_BOOL", "sometimes"))
+	problems, err = app.ValidateConfigSchema(false)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Contains(problems[0].Message, "invalid value for nfs_mount_enabled once environment variables are replaced")
+}
+
+// TestGetProjectTLDs makes sure the project_tld of listed projects is read
+// from their config files, the overrides included.
+func TestGetProjectTLDs(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	err := os.MkdirAll(filepath.Join(testDir, ".ddev"), 0755)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(testDir, ".ddev", "config.yaml"), []byte("name: projecttlds\nproject_tld: example.test\n"), 0644)
+	require.NoError(t, err)
+	err = globalconfig.SetProjectAppRoot(t.Name(), testDir)
+	require.NoError(t, err)
+	// nolint: errcheck
+	defer globalconfig.RemoveProjectInfo(t.Name())
+
+	tlds := GetProjectTLDs()
+	assert.Contains(tlds, DdevDefaultTLD)
+	assert.Contains(tlds, "example.test")
+
+	err = ioutil.WriteFile(filepath.Join(testDir, ".ddev", "config.local.yaml"), []byte("project_tld: local.test\n"), 0644)
+	require.NoError(t, err)
+	tlds = GetProjectTLDs()
+	assert.Contains(tlds, "local.test")
+	assert.NotContains(tlds, "example.test")
+}
+
+// TestEffectiveConfig tests that the effective config reports which config
+// file set each key, the last override winning.
+func TestEffectiveConfig(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	err := os.MkdirAll(filepath.Join(testDir, ".ddev"), 0755)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(testDir, ".ddev", "config.yaml"), []byte("name: effective\ntype: php\nphp_version: \"7.2\"\nrouter_http_port: \"8080\"\nmariadb_version: \"10.1\"\n"), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(testDir, ".ddev", "config.a.yaml"), []byte("php_version: \"7.3\"\nrouter_http_port: \"8081\"\n"), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(testDir, ".ddev", "config.b.yaml"), []byte("router_http_port: \"8082\"\n"), 0644)
+	require.NoError(t, err)
+
+	app, err := NewApp(testDir, true, ProviderDefault)
+	require.NoError(t, err)
+	effective, err := app.EffectiveConfig(true)
+	require.NoError(t, err)
+
+	byKey := map[string]ConfigKeySource{}
+	for _, e := range effective {
+		byKey[e.Key] = e
+	}
+	assert.Equal(ConfigKeySource{Key: "name", Value: "effective", Source: filepath.Join(".ddev", "config.yaml")}, byKey["name"])
+	assert.Equal(ConfigKeySource{Key: "php_version", Value: "7.3", Source: filepath.Join(".ddev", "config.a.yaml")}, byKey["php_version"])
+	assert.Equal(ConfigKeySource{Key: "router_http_port", Value: "8082", Source: filepath.Join(".ddev", "config.b.yaml")}, byKey["router_http_port"])
+	assert.Equal(ConfigKeySource{Key: "webserver_type", Value: WebserverDefault, Source: ConfigSourceDefault}, byKey["webserver_type"])
+	assert.Equal("APIVersion", effective[0].Key)
+
+	// The dbimage follows mariadb_version, which is reported as derived.
+	assert.Equal(ConfigKeySource{Key: "mariadb_version", Value: "10.1", Source: filepath.Join(".ddev", "config.yaml")}, byKey["mariadb_version"])
+	assert.Equal(ConfigKeySource{Key: "dbimage", Value: version.GetDBImage("10.1"), Source: ConfigSourceDerived}, byKey["dbimage"])
+
+	// Without the overrides the values are those of config.yaml, even though
+	// app was loaded with them.
+	effective, err = app.EffectiveConfig(false)
+	require.NoError(t, err)
+	byKey = map[string]ConfigKeySource{}
+	for _, e := range effective {
+		byKey[e.Key] = e
+	}
+	assert.Equal(ConfigKeySource{Key: "php_version", Value: "7.2", Source: filepath.Join(".ddev", "config.yaml")}, byKey["php_version"])
+	assert.Equal(ConfigKeySource{Key: "router_http_port", Value: "8080", Source: filepath.Join(".ddev", "config.yaml")}, byKey["router_http_port"])
+	assert.Equal(ConfigKeySource{Key: "webserver_type", Value: WebserverDefault, Source: ConfigSourceDefault}, byKey["webserver_type"])
+	assert.Equal("8082", app.RouterHTTPPort)
+}
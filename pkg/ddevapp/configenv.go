@@ -0,0 +1,157 @@
+package ddevapp
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+// configEnvRegex matches the ${VAR}, ${VAR:-default} and ${VAR-default}
+// references in config files, and the $$ escape for a literal $.
+var configEnvRegex = regexp.MustCompile(`\$\$|\$\{([A-Za-z_][A-Za-z0-9_]*)(?:(:?-)([^}]*))?\}`)
+
+// configEnvInterpolation is the state of interpolating one config file.
+type configEnvInterpolation struct {
+	// unset are the referenced variables that aren't set and have no default.
+	unset []string
+	// changed is set if any value had a reference or a $$ escape.
+	changed bool
+}
+
+// interpolateConfigEnv replaces the environment variable references in the
+// string values of a config file with their values, like docker-compose
+// does. ${VAR:-default} uses the default if VAR is unset or empty,
+// ${VAR-default} only if it's unset. Since only parsed values are replaced, a
+// variable's value can't change the structure of the file. Keys and the
+// hooks, whose commands run in a shell with its own variables, are left
+// alone. A value that's just a reference to a variable set to a number or
+// true/false gets that type, so it can be used for any key.
+// It returns the config re-encoded as YAML, or the unchanged source if it
+// has no references, and the referenced variables that aren't set and have
+// no default.
+func interpolateConfigEnv(source []byte) ([]byte, []string, error) {
+	doc, changed, unset, err := interpolateConfigEnvMap(source)
+	if err != nil || !changed {
+		return source, unset, err
+	}
+	interpolated, err := yaml.Marshal(doc)
+	return interpolated, unset, err
+}
+
+// interpolateConfigEnvMap is interpolateConfigEnv, returning the top-level
+// keys of the config file with their interpolated values.
+func interpolateConfigEnvMap(source []byte) (yaml.MapSlice, bool, []string, error) {
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(source, &doc); err != nil {
+		return nil, false, nil, err
+	}
+	state := &configEnvInterpolation{}
+	for i, item := range doc {
+		if item.Key == "hooks" {
+			continue
+		}
+		doc[i].Value = state.value(item.Value)
+	}
+	return doc, state.changed, state.unset, nil
+}
+
+// value interpolates the strings in a value, in lists and maps too.
+func (state *configEnvInterpolation) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return state.scalar(v)
+	case []interface{}:
+		for i := range v {
+			v[i] = state.value(v[i])
+		}
+	case yaml.MapSlice:
+		for i := range v {
+			v[i].Value = state.value(v[i].Value)
+		}
+	}
+	return value
+}
+
+// scalar interpolates a string value.
+func (state *configEnvInterpolation) scalar(s string) interface{} {
+	replaced := configEnvRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		if ref == "$$" {
+			return "$"
+		}
+		m := configEnvRegex.FindStringSubmatch(ref)
+		name, operator, defaultValue := m[1], m[2], m[3]
+		value, isSet := os.LookupEnv(name)
+		switch {
+		case operator == ":-" && value == "":
+			return defaultValue
+		case operator == "-" && !isSet:
+			return defaultValue
+		case !isSet && operator == "":
+			state.unset = append(state.unset, name)
+		}
+		return value
+	})
+	if !configEnvRegex.MatchString(s) {
+		return s
+	}
+	state.changed = true
+
+	if s != "$$" && configEnvRegex.FindString(s) == s {
+		if replaced == "true" || replaced == "false" {
+			return replaced == "true"
+		}
+		if n, err := strconv.Atoi(replaced); err == nil && strconv.Itoa(n) == replaced {
+			return n
+		}
+	}
+	return replaced
+}
+
+// configUsesEnv returns true if the config file has environment variable
+// references that interpolateConfigEnv would replace.
+func configUsesEnv(source []byte) bool {
+	_, changed, _, err := interpolateConfigEnvMap(source)
+	return err == nil && changed
+}
+
+// keepConfigEnvReferences takes the new content of a config file whose
+// current source uses environment variable references, and puts back the
+// references of every top-level key whose value didn't change, so writing
+// the config doesn't replace them with the values of one developer.
+func keepConfigEnvReferences(current []byte, updated []byte) ([]byte, error) {
+	var original yaml.MapSlice
+	if err := yaml.Unmarshal(current, &original); err != nil {
+		return nil, err
+	}
+	interpolated, _, _, err := interpolateConfigEnvMap(current)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.MapSlice
+	if err = yaml.Unmarshal(updated, &doc); err != nil {
+		return nil, err
+	}
+
+	for i, item := range doc {
+		for j, orig := range original {
+			if orig.Key != item.Key {
+				continue
+			}
+			if !reflect.DeepEqual(orig.Value, interpolated[j].Value) && configValuesEqual(interpolated[j].Value, item.Value) {
+				doc[i].Value = orig.Value
+			}
+			break
+		}
+	}
+	return yaml.Marshal(doc)
+}
+
+// configValuesEqual tells whether two decoded YAML values are the same,
+// ignoring the difference between a number or boolean and its string.
+func configValuesEqual(a interface{}, b interface{}) bool {
+	return reflect.DeepEqual(a, b) || fmt.Sprint(a) == fmt.Sprint(b)
+}
@@ -0,0 +1,131 @@
+package ddevapp
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigSourceDefault is the source of config values no config file sets.
+const ConfigSourceDefault = "default"
+
+// ConfigSourceDerived is the source of config values ddev works out from
+// other values, like the dbimage of the mariadb_version, or forces, like the
+// php_version of some project types.
+const ConfigSourceDerived = "derived"
+
+// ConfigKeySource is the effective value of a config.yaml key and where it
+// comes from: the last config file setting it, the built-in default, or
+// ddev deriving it.
+type ConfigKeySource struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+// EffectiveConfig returns each config.yaml key of the project with its
+// value and the config file that set it, relative to the project root, in
+// the order of the DdevApp fields. The config files are config.yaml and,
+// if includeOverrides is set, the config.*.yaml files, the later winning.
+// The values are read from the config files again, rather than taken from
+// app, which may have been loaded with other files or changed since.
+func (app *DdevApp) EffectiveConfig(includeOverrides bool) ([]ConfigKeySource, error) {
+	loaded, err := NewApp(app.AppRoot, includeOverrides, "")
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{loaded.ConfigPath}
+	if includeOverrides {
+		overrides, err := loaded.configOverrideFiles()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, overrides...)
+	}
+
+	// The source and the value each key has in the last file setting it.
+	sources := map[string]string{}
+	fileValues := map[string]interface{}{}
+	for _, file := range files {
+		source, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		source, _, err = interpolateConfigEnv(source)
+		if err != nil {
+			return nil, err
+		}
+		keys := map[string]interface{}{}
+		if err = yaml.Unmarshal(source, &keys); err != nil {
+			return nil, err
+		}
+		fileApp := DdevApp{}
+		if err = yaml.Unmarshal(source, &fileApp); err != nil {
+			return nil, err
+		}
+		name := file
+		if rel, err := filepath.Rel(loaded.AppRoot, file); err == nil {
+			name = rel
+		}
+		for key, value := range configKeyValues(&fileApp) {
+			if _, ok := keys[key]; ok {
+				sources[key] = name
+				fileValues[key] = value
+			}
+		}
+	}
+
+	defaults := &DdevApp{AppRoot: loaded.AppRoot}
+	defaults.setDefaults()
+	defaults.Provider = ProviderDefault
+	defaultValues := configKeyValues(defaults)
+
+	effective := []ConfigKeySource{}
+	fields := reflect.TypeOf(*loaded)
+	values := reflect.ValueOf(*loaded)
+	for i := 0; i < fields.NumField(); i++ {
+		key := configFieldKey(fields.Field(i))
+		if key == "" || !values.Field(i).CanInterface() {
+			continue
+		}
+		value := values.Field(i).Interface()
+		source, ok := sources[key]
+		switch {
+		case ok && !reflect.DeepEqual(fileValues[key], value):
+			source = ConfigSourceDerived
+		case !ok && reflect.DeepEqual(defaultValues[key], value):
+			source = ConfigSourceDefault
+		case !ok:
+			source = ConfigSourceDerived
+		}
+		effective = append(effective, ConfigKeySource{Key: key, Value: value, Source: source})
+	}
+	return effective, nil
+}
+
+// configFieldKey returns the config.yaml key of a DdevApp field, or "" if
+// it isn't one.
+func configFieldKey(field reflect.StructField) string {
+	key := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if key == "-" {
+		return ""
+	}
+	return key
+}
+
+// configKeyValues returns the values of the config.yaml keys of app.
+func configKeyValues(app *DdevApp) map[string]interface{} {
+	keyValues := map[string]interface{}{}
+	fields := reflect.TypeOf(*app)
+	values := reflect.ValueOf(*app)
+	for i := 0; i < fields.NumField(); i++ {
+		if key := configFieldKey(fields.Field(i)); key != "" && values.Field(i).CanInterface() {
+			keyValues[key] = values.Field(i).Interface()
+		}
+	}
+	return keyValues
+}
@@ -0,0 +1,376 @@
+package ddevapp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/drud/ddev/pkg/fileutil"
+	"github.com/drud/ddev/pkg/nodeps"
+	"github.com/drud/ddev/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// ConfigSchemaError is a problem found checking a config file against the
+// config.yaml schema, with the position of the offending key or value.
+type ConfigSchemaError struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// Error formats the problem the way compilers do, file:line:column: message.
+func (e ConfigSchemaError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// yamlLineErrorRegex splits the "line N: problem" messages of yaml.v2.
+var yamlLineErrorRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlUnknownFieldRegex matches the yaml.v2 strict mode error for unknown keys.
+var yamlUnknownFieldRegex = regexp.MustCompile(`^field (\S+) not found in type (\S+)$`)
+
+// yamlTypeErrorRegex matches the yaml.v2 error for a value of the wrong type.
+var yamlTypeErrorRegex = regexp.MustCompile("^cannot unmarshal (!!\\w+)(?: (`.*`))? into (\\S+)$")
+
+// yamlKeyRegex finds the key on a line of YAML, for a mapping or a list item
+// holding a mapping.
+var yamlKeyRegex = regexp.MustCompile(`^(\s*(?:-\s+)?)([^\s:#'"][^:#]*?)\s*:(?:\s|$)`)
+
+// yamlTopLevelKeyRegex finds the keys of the top-level mapping.
+var yamlTopLevelKeyRegex = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*:`)
+
+// configSchemaTypes are the config.yaml types yaml.v2 names in its errors.
+var configSchemaTypes = map[string]reflect.Type{
+	"ddevapp.DdevApp":         reflect.TypeOf(DdevApp{}),
+	"ddevapp.Command":         reflect.TypeOf(Command{}),
+	"ddevapp.DrupalMultisite": reflect.TypeOf(DrupalMultisite{}),
+}
+
+// yamlTagNames describes the YAML tags in yaml.v2 errors.
+var yamlTagNames = map[string]string{
+	"!!str":   "a string",
+	"!!int":   "a number",
+	"!!float": "a number",
+	"!!bool":  "true or false",
+	"!!null":  "an empty value",
+	"!!seq":   "a list",
+	"!!map":   "a mapping",
+}
+
+// goTypeNames describes the Go types of config.yaml fields for yaml.v2 errors.
+var goTypeNames = map[string]string{
+	"string":                       "a string",
+	"bool":                         "true or false",
+	"[]string":                     "a list of strings",
+	"map[string]string":            "a mapping of strings",
+	"map[string][]ddevapp.Command": "a mapping of hooks to lists of tasks",
+	"[]ddevapp.Command":            "a list of tasks",
+	"ddevapp.Command":              "a task",
+	"[]ddevapp.DrupalMultisite":    "a list of sites",
+	"ddevapp.DrupalMultisite":      "a site",
+}
+
+// ValidateConfigSchema checks config.yaml, and the config.*.yaml files if
+// includeOverrides is set, for unknown keys, values of the wrong type and
+// values that aren't among the valid ones. The error is only for files that
+// can't be read; the problems found are returned.
+func (app *DdevApp) ValidateConfigSchema(includeOverrides bool) ([]ConfigSchemaError, error) {
+	files := []string{app.ConfigPath}
+	if includeOverrides {
+		overrides, err := app.configOverrideFiles()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, overrides...)
+	}
+
+	problems := []ConfigSchemaError{}
+	for _, file := range files {
+		fileProblems, err := app.validateConfigFileSchema(file)
+		if err != nil {
+			return nil, err
+		}
+		problems = append(problems, fileProblems...)
+	}
+	return problems, nil
+}
+
+// WarnConfigSchemaProblems warns about the first problem ValidateConfigSchema
+// finds, if any, since typos and wrong values in the config files would
+// otherwise be silently ignored.
+func (app *DdevApp) WarnConfigSchemaProblems(includeOverrides bool) {
+	if !fileutil.FileExists(app.ConfigPath) {
+		return
+	}
+	if problems, err := app.ValidateConfigSchema(includeOverrides); err == nil && len(problems) > 0 {
+		util.Warning("%v (run 'ddev config validate' to see all %d problem(s))", problems[0], len(problems))
+	}
+}
+
+// validateConfigFileSchema checks a single config file against the schema.
+func (app *DdevApp) validateConfigFileSchema(file string) ([]ConfigSchemaError, error) {
+	source, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(string(source), "\n")
+	problems := []ConfigSchemaError{}
+
+	// Keys and types are checked on the file as written, except the types
+	// of values with environment variable references, which are only known
+	// once they're interpolated.
+	var probe DdevApp
+	err = yaml.UnmarshalStrict(source, &probe)
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		for _, msg := range typeErr.Errors {
+			problem := yamlErrorProblem(file, lines, msg)
+			if problem.Line < 1 || problem.Line > len(lines) || !configEnvRegex.MatchString(lines[problem.Line-1]) {
+				problems = append(problems, problem)
+			}
+		}
+	} else if err != nil {
+		// A syntax error means nothing else can be checked.
+		return append(problems, yamlErrorProblem(file, lines, err.Error())), nil
+	}
+
+	keys := map[string]int{}
+	for i, line := range lines {
+		if m := yamlTopLevelKeyRegex.FindStringSubmatch(line); m != nil {
+			keys[m[1]] = i + 1
+		}
+	}
+
+	interpolated, _, err := interpolateConfigEnv(source)
+	if err != nil {
+		return nil, err
+	}
+	probe = DdevApp{}
+	err = yaml.Unmarshal(interpolated, &probe)
+	if typeErr, ok := err.(*yaml.TypeError); ok && string(interpolated) != string(source) {
+		// The interpolated config is re-encoded, so its errors are reported
+		// at the top-level key they're in, if it has a reference.
+		refKeys := configEnvReferenceKeys(lines)
+		interpolatedLines := strings.Split(string(interpolated), "\n")
+		for _, msg := range typeErr.Errors {
+			problem := yamlErrorProblem(file, interpolatedLines, msg)
+			key := yamlTopLevelKeyAbove(interpolatedLines, problem.Line)
+			if !refKeys[key] {
+				continue
+			}
+			problem.Message = strings.TrimPrefix(problem.Message, "invalid value for "+key+": ")
+			problems = append(problems, valueProblem(file, lines, keys[key], fmt.Sprintf("invalid value for %s once environment variables are replaced: %s", key, problem.Message)))
+		}
+	}
+
+	if err = validateCommandYaml(source); err != nil {
+		problems = append(problems, keyProblem(file, lines, keys["hooks"], err.Error()))
+	}
+
+	enums := []struct {
+		key     string
+		value   string
+		valid   bool
+		options []string
+	}{
+		{"type", probe.Type, nodeps.ArrayContainsString(app.GetValidProjectTypes(), probe.Type), app.GetValidProjectTypes()},
+		{"php_version", probe.PHPVersion, IsValidPHPVersion(probe.PHPVersion), GetValidPHPVersions()},
+		{"webserver_type", probe.WebserverType, IsValidWebserverType(probe.WebserverType), GetValidWebserverTypes()},
+		{"mariadb_version", probe.MariaDBVersion, IsValidMariaDBVersion(probe.MariaDBVersion), GetValidMariaDBVersions()},
+		{"provider", probe.Provider, IsValidProvider(probe.Provider), GetValidProviders()},
+		{"profiler", probe.Profiler, probe.Profiler == "" || IsValidProfiler(probe.Profiler), GetValidProfilers()},
+		{"nodejs_version", probe.NodeJSVersion, probe.NodeJSVersion == "" || IsValidNodeJSVersion(probe.NodeJSVersion), GetValidNodeJSVersions()},
+		{"composer_version", probe.ComposerVersion, IsValidComposerVersion(probe.ComposerVersion), nil},
+		{"omit_containers", strings.Join(probe.OmitContainers, ","), IsValidOmitContainers(probe.OmitContainers), GetValidOmitContainers()},
+	}
+	for _, e := range enums {
+		line, ok := keys[e.key]
+		if !ok || e.valid {
+			continue
+		}
+		msg := fmt.Sprintf("invalid %s %q", e.key, e.value)
+		if len(e.options) > 0 {
+			sort.Strings(e.options)
+			msg += fmt.Sprintf(", must be one of %s", strings.Join(e.options, ", "))
+		}
+		problems = append(problems, valueProblem(file, lines, line, msg))
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+	return problems, nil
+}
+
+// configEnvReferenceKeys returns the top-level keys of a config file whose
+// values have environment variable references.
+func configEnvReferenceKeys(lines []string) map[string]bool {
+	refKeys := map[string]bool{}
+	key := ""
+	for _, line := range lines {
+		if m := yamlTopLevelKeyRegex.FindStringSubmatch(line); m != nil {
+			key = m[1]
+		}
+		if key != "" && !strings.HasPrefix(strings.TrimSpace(line), "#") && configEnvRegex.MatchString(line) {
+			refKeys[key] = true
+		}
+	}
+	return refKeys
+}
+
+// yamlTopLevelKeyAbove returns the top-level key the line (counted from 1)
+// belongs to, if any.
+func yamlTopLevelKeyAbove(lines []string, line int) string {
+	for i := line - 1; i >= 0 && i < len(lines); i-- {
+		if m := yamlTopLevelKeyRegex.FindStringSubmatch(lines[i]); m != nil {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// yamlErrorProblem turns a yaml.v2 error message into a ConfigSchemaError,
+// rewording the messages of strict mode and type errors.
+func yamlErrorProblem(file string, lines []string, msg string) ConfigSchemaError {
+	m := yamlLineErrorRegex.FindStringSubmatch(msg)
+	if m == nil {
+		return ConfigSchemaError{File: file, Line: 1, Column: 1, Message: msg}
+	}
+	line, _ := strconv.Atoi(m[1])
+	msg = m[2]
+
+	if f := yamlUnknownFieldRegex.FindStringSubmatch(msg); f != nil {
+		msg = "unknown key " + f[1]
+		if suggestion := closestYAMLKey(f[1], configSchemaTypes[f[2]]); suggestion != "" {
+			msg += fmt.Sprintf(", did you mean %s?", suggestion)
+		}
+		return keyProblem(file, lines, line, msg)
+	}
+
+	if t := yamlTypeErrorRegex.FindStringSubmatch(msg); t != nil {
+		expected, ok := goTypeNames[t[3]]
+		if !ok {
+			expected = t[3]
+		}
+		got, ok := yamlTagNames[t[1]]
+		if !ok {
+			got = t[1]
+		}
+		if t[2] != "" {
+			got += " " + t[2]
+		}
+		msg = fmt.Sprintf("expected %s, got %s", expected, got)
+		if key := yamlKeyOnLine(lines, line); key != "" {
+			msg = fmt.Sprintf("invalid value for %s: %s", key, msg)
+		}
+		return valueProblem(file, lines, line, msg)
+	}
+
+	return keyProblem(file, lines, line, msg)
+}
+
+// yamlKeyOnLine returns the key on the line (counted from 1), if any.
+func yamlKeyOnLine(lines []string, line int) string {
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	if m := yamlKeyRegex.FindStringSubmatch(lines[line-1]); m != nil {
+		return m[2]
+	}
+	return ""
+}
+
+// keyProblem reports msg at the start of the key on the line.
+func keyProblem(file string, lines []string, line int, msg string) ConfigSchemaError {
+	problem := ConfigSchemaError{File: file, Line: line, Column: 1, Message: msg}
+	if line < 1 || line > len(lines) {
+		return problem
+	}
+	if m := yamlKeyRegex.FindStringSubmatch(lines[line-1]); m != nil {
+		problem.Column = len(m[1]) + 1
+	} else {
+		problem.Column = len(lines[line-1]) - len(strings.TrimLeft(lines[line-1], " \t-")) + 1
+	}
+	return problem
+}
+
+// valueProblem reports msg at the start of the value on the line.
+func valueProblem(file string, lines []string, line int, msg string) ConfigSchemaError {
+	problem := keyProblem(file, lines, line, msg)
+	if line < 1 || line > len(lines) {
+		return problem
+	}
+	if m := yamlKeyRegex.FindStringSubmatchIndex(lines[line-1]); m != nil {
+		rest := lines[line-1][m[1]:]
+		if value := strings.TrimLeft(rest, " \t"); value != "" {
+			problem.Column = m[1] + len(rest) - len(value) + 1
+		}
+	}
+	return problem
+}
+
+// closestYAMLKey returns the key of the struct type t closest to key, if
+// it's close enough to be a likely typo.
+func closestYAMLKey(key string, t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	best := ""
+	bestDistance := len(key)/3 + 1
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if d := levenshtein(key, name); d <= bestDistance {
+			best = name
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// minInt returns the smallest of its arguments.
+func minInt(first int, rest ...int) int {
+	for _, v := range rest {
+		if v < first {
+			first = v
+		}
+	}
+	return first
+}
+
+// configOverrideFiles returns the config.*.yaml files, in the order they're loaded.
+func (app *DdevApp) configOverrideFiles() ([]string, error) {
+	return filepath.Glob(filepath.Join(filepath.Dir(app.ConfigPath), "config.*.y*ml"))
+}
@@ -0,0 +1,80 @@
+package ddevapp_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/testcommon"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateConfigSchema checks that unknown keys, values of the wrong type
+// and invalid values are reported with their file, line and column.
+func TestValidateConfigSchema(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	err := os.MkdirAll(filepath.Join(testDir, ".ddev"), 0755)
+	require.NoError(t, err)
+	configFile := filepath.Join(testDir, ".ddev", "config.yaml")
+	overrideFile := filepath.Join(testDir, ".ddev", "config.local.yaml")
+
+	err = ioutil.WriteFile(configFile, []byte("name: schema\ntype: drupal8\nphp_version: \"7.2\"\n"), 0644)
+	require.NoError(t, err)
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	problems, err := app.ValidateConfigSchema(true)
+	require.NoError(t, err)
+	assert.Empty(problems)
+
+	err = ioutil.WriteFile(configFile, []byte(`name: schema
+type: drupal8
+php_verison: "7.2"
+xdebug_enabled: sometimes
+webserver_type: lighttpd
+hooks:
+  post-start:
+    - exec: ls
+      serivce: db
+`), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(overrideFile, []byte("mariadb_version: \"5.5\"\n"), 0644)
+	require.NoError(t, err)
+
+	// The invalid hook stops NewApp, but the config can still be validated.
+	app, err = ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	assert.Error(err)
+	require.NotNil(t, app)
+	problems, err = app.ValidateConfigSchema(true)
+	require.NoError(t, err)
+
+	expected := []ddevapp.ConfigSchemaError{
+		{File: configFile, Line: 3, Column: 1, Message: "unknown key php_verison, did you mean php_version?"},
+		{File: configFile, Line: 4, Column: 17, Message: "invalid value for xdebug_enabled: expected true or false, got a string `sometimes`"},
+		{File: configFile, Line: 5, Column: 17, Message: `invalid webserver_type "lighttpd", must be one of apache-cgi, apache-fpm, nginx-fpm`},
+		{File: configFile, Line: 6, Column: 1, Message: "invalid task 'serivce' defined for post-start hook in config.yaml"},
+		{File: configFile, Line: 9, Column: 7, Message: "unknown key serivce, did you mean service?"},
+		{File: overrideFile, Line: 1, Column: 18, Message: `invalid mariadb_version "5.5", must be one of 10.1, 10.2`},
+	}
+	assert.Equal(expected, problems)
+	assert.Equal(configFile+":3:1: unknown key php_verison, did you mean php_version?", problems[0].Error())
+
+	// A syntax error stops the checks of the file.
+	err = ioutil.WriteFile(overrideFile, []byte("mariadb_version: [\n"), 0644)
+	require.NoError(t, err)
+	problems, err = app.ValidateConfigSchema(true)
+	require.NoError(t, err)
+	require.Len(t, problems, 6)
+	assert.Equal(overrideFile, problems[5].File)
+	assert.Contains(problems[5].Message, "did not find expected node content")
+
+	// Without the overrides only config.yaml is checked.
+	problems, err = app.ValidateConfigSchema(false)
+	require.NoError(t, err)
+	assert.Len(problems, 5)
+}
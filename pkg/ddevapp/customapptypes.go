@@ -0,0 +1,270 @@
+package ddevapp
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/drud/ddev/pkg/appports"
+	"github.com/drud/ddev/pkg/dockerutil"
+	"github.com/drud/ddev/pkg/fileutil"
+	"github.com/drud/ddev/pkg/output"
+	"github.com/drud/ddev/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// CustomAppTypesDir is the directory in .ddev where a project can define its
+// own project types, one <name>.yaml per type.
+const CustomAppTypesDir = "apptypes"
+
+var customAppTypeNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// CustomAppType is a project type defined in .ddev/apptypes/<name>.yaml.
+type CustomAppType struct {
+	// Name is the name of the type, taken from the file name.
+	Name string `yaml:"-"`
+	// Detect is a list of glob patterns relative to the project root;
+	// the project is of this type if any of them matches.
+	Detect []string `yaml:"detect"`
+	// Docroot is the default docroot of the type.
+	Docroot string `yaml:"docroot,omitempty"`
+	// UploadDir is the default upload dir of the type, relative to the docroot.
+	UploadDir string `yaml:"upload_dir,omitempty"`
+	// Hooks are the hook suggestions added as comments to config.yaml.
+	Hooks string `yaml:"hooks,omitempty"`
+	// SettingsFiles are the settings files ddev writes for the type.
+	SettingsFiles []CustomSettingsFile `yaml:"settings_files,omitempty"`
+
+	// dir is the directory the type was read from.
+	dir string
+}
+
+// CustomSettingsFile is a settings file of a custom project type, rendered
+// from a Go text/template with CustomSettingsVars.
+type CustomSettingsFile struct {
+	// Path is where the file is written, relative to the project root.
+	Path string `yaml:"path"`
+	// Template is the template of the file.
+	Template string `yaml:"template,omitempty"`
+	// TemplateFile is a file holding the template, relative to .ddev/apptypes.
+	TemplateFile string `yaml:"template_file,omitempty"`
+}
+
+// CustomSettingsVars are the values available to the settings templates of
+// custom project types.
+type CustomSettingsVars struct {
+	Name             string
+	Hostname         string
+	URL              string
+	HTTPURL          string
+	HTTPSURL         string
+	URLs             []string
+	Docroot          string
+	UploadDir        string
+	DatabaseName     string
+	DatabaseUsername string
+	DatabasePassword string
+	DatabaseHost     string
+	DatabasePort     string
+	DockerIP         string
+	DBPublishedPort  int
+	Signature        string
+}
+
+// ReadCustomAppTypes reads the custom project types defined in dir, returning
+// them keyed by name. A missing dir just means there are none. Types that
+// can't be used are skipped with a warning, so one broken file doesn't keep
+// the project from loading.
+func ReadCustomAppTypes(dir string) (map[string]*CustomAppType, error) {
+	types := make(map[string]*CustomAppType)
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, file := range files {
+		appType, err := readCustomAppType(dir, file)
+		if err != nil {
+			util.Warning("Skipping custom project type: %v", err)
+			continue
+		}
+		types[appType.Name] = appType
+	}
+	return types, nil
+}
+
+// readCustomAppType reads and validates the custom project type in file.
+func readCustomAppType(dir string, file string) (*CustomAppType, error) {
+	name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+	if !customAppTypeNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid project type name %s from %s, use only lowercase letters, digits, '-' and '_'", name, file)
+	}
+	if _, ok := appTypeMatrix[name]; ok {
+		return nil, fmt.Errorf("%s defines project type %s, which is already a built-in project type", file, name)
+	}
+
+	contents, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	appType := &CustomAppType{}
+	if err = yaml.Unmarshal(contents, appType); err != nil {
+		return nil, fmt.Errorf("unable to parse project type %s: %v", file, err)
+	}
+	appType.Name = name
+	appType.dir = dir
+
+	for _, f := range appType.SettingsFiles {
+		// Settings files must stay inside the project.
+		cleanPath := filepath.Clean(f.Path)
+		if f.Path == "" || filepath.IsAbs(f.Path) || cleanPath == ".." || strings.HasPrefix(cleanPath, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("settings_files in %s need a path inside the project root, not %q", file, f.Path)
+		}
+		if (f.Template == "") == (f.TemplateFile == "") {
+			return nil, fmt.Errorf("settings file %s in %s needs exactly one of template or template_file", f.Path, file)
+		}
+	}
+	return appType, nil
+}
+
+// GetCustomAppTypes returns the names of the custom project types of the project.
+func (app *DdevApp) GetCustomAppTypes() []string {
+	names := make([]string, 0, len(app.customAppTypes))
+	for name := range app.customAppTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// appTypeFuncs returns the functions ddev calls for the custom project type.
+func (t *CustomAppType) appTypeFuncs() AppTypeFuncs {
+	funcs := AppTypeFuncs{
+		uploadDir: func(app *DdevApp) string {
+			if app.UploadDir != "" {
+				return app.UploadDir
+			}
+			return t.UploadDir
+		},
+		hookDefaultComments: func() []byte {
+			return []byte(t.Hooks)
+		},
+		apptypeSettingsPaths: func(app *DdevApp) {
+			if len(t.SettingsFiles) > 0 {
+				app.SiteSettingsPath = filepath.Join(app.AppRoot, t.SettingsFiles[0].Path)
+				app.SiteDdevSettingsFile = app.SiteSettingsPath
+			}
+		},
+		appTypeDetect: t.detect,
+		configOverrideAction: func(app *DdevApp) error {
+			if app.Docroot == "" {
+				app.Docroot = t.Docroot
+			}
+			return nil
+		},
+	}
+	if len(t.SettingsFiles) > 0 {
+		funcs.settingsCreator = t.createSettingsFiles
+		funcs.postStartAction = func(app *DdevApp) error {
+			if _, err := app.CreateSettingsFile(); err != nil {
+				return fmt.Errorf("failed to write settings file %s: %v", app.SiteDdevSettingsFile, err)
+			}
+			return nil
+		}
+	}
+	if t.UploadDir != "" {
+		funcs.importFilesAction = drupalImportFilesAction
+	}
+	return funcs
+}
+
+// detect returns the files in the project matching the type's detection globs.
+func (t *CustomAppType) detect(app *DdevApp) []string {
+	return app.existingFiles("", t.Detect...)
+}
+
+// createSettingsFiles renders each of the type's settings files, skipping
+// any that already exist without the ddev signature.
+func (t *CustomAppType) createSettingsFiles(app *DdevApp) (string, error) {
+	vars := newCustomSettingsVars(app)
+
+	for _, f := range t.SettingsFiles {
+		target := filepath.Join(app.AppRoot, f.Path)
+		if fileutil.FileExists(target) {
+			signatureFound, err := fileutil.FgrepStringInFile(target, DdevFileSignature)
+			if err != nil {
+				return "", err
+			}
+			if !signatureFound {
+				util.Warning("%s already exists and is managed by the user.", f.Path)
+				continue
+			}
+		}
+
+		text := f.Template
+		if f.TemplateFile != "" {
+			b, err := ioutil.ReadFile(filepath.Join(t.dir, f.TemplateFile))
+			if err != nil {
+				return "", fmt.Errorf("unable to read template for %s: %v", f.Path, err)
+			}
+			text = string(b)
+		}
+		tmpl, err := template.New(f.Path).Funcs(getTemplateFuncMap()).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return "", fmt.Errorf("invalid template for %s: %v", f.Path, err)
+		}
+		var buf bytes.Buffer
+		if err = tmpl.Execute(&buf, vars); err != nil {
+			return "", fmt.Errorf("failed to render %s: %v", f.Path, err)
+		}
+		if !bytes.Contains(buf.Bytes(), []byte(DdevFileSignature)) {
+			util.Warning("The template for %s doesn't include {{ .Signature }}, so ddev will treat the file as user-managed from now on", f.Path)
+		}
+
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return "", err
+		}
+		output.UserOut.Printf("Generating %s for project type %s", f.Path, t.Name)
+		if err = ioutil.WriteFile(target, buf.Bytes(), 0644); err != nil {
+			return "", err
+		}
+	}
+
+	return app.SiteDdevSettingsFile, nil
+}
+
+// newCustomSettingsVars collects the values for the settings templates of app.
+func newCustomSettingsVars(app *DdevApp) *CustomSettingsVars {
+	dockerIP, _ := dockerutil.GetDockerIP()
+	dbPublishedPort, _ := app.GetPublishedPort("db")
+	urls := app.GetAllURLs()
+	url := app.getSettingsURL(false)
+	if len(urls) > 0 {
+		url = urls[0]
+	}
+
+	return &CustomSettingsVars{
+		Name:             app.Name,
+		Hostname:         app.GetHostname(),
+		URL:              url,
+		HTTPURL:          app.getSettingsURL(false),
+		HTTPSURL:         app.getSettingsURL(true),
+		URLs:             urls,
+		Docroot:          app.Docroot,
+		UploadDir:        app.GetUploadDir(),
+		DatabaseName:     "db",
+		DatabaseUsername: "db",
+		DatabasePassword: "db",
+		DatabaseHost:     "db",
+		DatabasePort:     appports.GetPort("db"),
+		DockerIP:         dockerIP,
+		DBPublishedPort:  dbPublishedPort,
+		Signature:        DdevFileSignature,
+	}
+}
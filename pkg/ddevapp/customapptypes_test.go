@@ -0,0 +1,95 @@
+package ddevapp_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/testcommon"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCustomAppTypes makes sure a project type defined in .ddev/apptypes is
+// detected, validated and writes its templated settings files.
+func TestCustomAppTypes(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	appTypesDir := filepath.Join(testDir, ".ddev", ddevapp.CustomAppTypesDir)
+	err := os.MkdirAll(appTypesDir, 0755)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(appTypesDir, "myframework.yaml"), []byte(`
+detect:
+  - bin/myframework
+docroot: web
+upload_dir: uploads
+settings_files:
+  - path: config/db.php
+    template: |
+      <?php
+      // {{ .Signature }}
+      $db = 'mysql://{{ .DatabaseUsername }}:{{ .DatabasePassword }}@{{ .DatabaseHost }}:{{ .DatabasePort }}/{{ .DatabaseName }}';
+  - path: config/site.ini
+    template_file: site.ini.tmpl
+`), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(appTypesDir, "site.ini.tmpl"), []byte("; {{ .Signature }}\nurl={{ .URL }}\n"), 0644)
+	require.NoError(t, err)
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	assert.Equal([]string{"myframework"}, app.GetCustomAppTypes())
+	assert.Contains(app.GetValidProjectTypes(), "myframework")
+	assert.Equal(ddevapp.AppTypePHP, app.DetectAppType())
+
+	err = os.MkdirAll(filepath.Join(testDir, "bin"), 0755)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(testDir, "bin", "myframework"), []byte(""), 0755)
+	require.NoError(t, err)
+	assert.Equal("myframework", app.DetectAppType())
+
+	app.Type = "myframework"
+	assert.NoError(app.ValidateConfig())
+	assert.NoError(app.ConfigFileOverrideAction())
+	assert.Equal("web", app.Docroot)
+	assert.Equal("uploads", app.GetUploadDir())
+
+	settingsFile, err := app.CreateSettingsFile()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(testDir, "config", "db.php"), settingsFile)
+	contents, err := ioutil.ReadFile(settingsFile)
+	require.NoError(t, err)
+	assert.Contains(string(contents), "$db = 'mysql://db:db@db:3306/db';")
+	contents, err = ioutil.ReadFile(filepath.Join(testDir, "config", "site.ini"))
+	require.NoError(t, err)
+	assert.Contains(string(contents), "url="+app.GetAllURLs()[0]+"\n")
+
+	// A settings file without the signature belongs to the user.
+	err = ioutil.WriteFile(settingsFile, []byte("<?php\n"), 0644)
+	require.NoError(t, err)
+	_, err = app.CreateSettingsFile()
+	assert.NoError(err)
+	contents, err = ioutil.ReadFile(settingsFile)
+	require.NoError(t, err)
+	assert.Equal("<?php\n", string(contents))
+
+	// Types that can't be used are skipped: ones taking over built-in type
+	// names, unparseable ones and ones writing outside the project.
+	for name, contents := range map[string]string{
+		ddevapp.AppTypeDrupal8: "detect: [index.php]\n",
+		"broken":               "detect: [index.php\n",
+		"outside":              "settings_files:\n  - path: config/../../db.php\n    template: x\n",
+	} {
+		err = ioutil.WriteFile(filepath.Join(appTypesDir, name+".yaml"), []byte(contents), 0644)
+		require.NoError(t, err)
+	}
+	app, err = ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	assert.Equal([]string{"myframework"}, app.GetCustomAppTypes())
+}
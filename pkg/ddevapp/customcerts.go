@@ -0,0 +1,195 @@
+package ddevapp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/drud/ddev/pkg/exec"
+	"github.com/drud/ddev/pkg/fileutil"
+	"github.com/drud/ddev/pkg/util"
+)
+
+// CustomCertsDir is the directory in .ddev where per-hostname certificates
+// (<hostname>.crt and <hostname>.key) can be placed to be used instead of
+// the ones generated with mkcert.
+const CustomCertsDir = "certs"
+
+// routerCustomCertsDir is where the router looks for <hostname>.crt and
+// <hostname>.key, on the ddev-global-cache volume so the certificates
+// survive the router being recreated.
+const routerCustomCertsDir = "/mnt/ddev-global-cache/custom_certs"
+
+// GetCustomCerts returns the custom certificates of the project, as a map of
+// hostname to the path of the certificate without its .crt/.key extension.
+// Only certificates named after one of the project's hostnames are returned.
+func (app *DdevApp) GetCustomCerts() (map[string]string, error) {
+	certs := make(map[string]string)
+	certsDir := app.GetConfigPath(CustomCertsDir)
+	if !fileutil.FileExists(certsDir) {
+		return certs, nil
+	}
+
+	for _, hostname := range app.GetHostnames() {
+		base := filepath.Join(certsDir, hostname)
+		crtExists := fileutil.FileExists(base + ".crt")
+		keyExists := fileutil.FileExists(base + ".key")
+		if crtExists != keyExists {
+			return nil, fmt.Errorf("custom certificate for %s needs both %s.crt and %s.key in %s", hostname, hostname, hostname, certsDir)
+		}
+		if crtExists {
+			certs[hostname] = base
+		}
+	}
+	return certs, nil
+}
+
+// ValidateCustomCerts makes sure every custom certificate of the project can be
+// loaded with its key and actually covers the hostname it's named after.
+func (app *DdevApp) ValidateCustomCerts() error {
+	certs, err := app.GetCustomCerts()
+	if err != nil {
+		return err
+	}
+
+	for hostname, base := range certs {
+		pair, err := tls.LoadX509KeyPair(base+".crt", base+".key")
+		if err != nil {
+			return fmt.Errorf("unable to load custom certificate %s.crt: %v", base, err)
+		}
+		leaf, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			return fmt.Errorf("unable to parse custom certificate %s.crt: %v", base, err)
+		}
+		if err = leaf.VerifyHostname(hostname); err != nil {
+			return fmt.Errorf("custom certificate %s.crt does not cover %s: %v", base, hostname, err)
+		}
+		if time.Now().After(leaf.NotAfter) {
+			util.Warning("Custom certificate %s.crt expired on %s", base, leaf.NotAfter.Format("2006-01-02"))
+		}
+	}
+
+	// Certificates that don't match a hostname are probably a mistake.
+	files, _ := ioutil.ReadDir(app.GetConfigPath(CustomCertsDir))
+	for _, f := range files {
+		name := f.Name()
+		if !strings.HasSuffix(name, ".crt") {
+			continue
+		}
+		if _, ok := certs[strings.TrimSuffix(name, ".crt")]; !ok {
+			util.Warning("Custom certificate %s doesn't match any hostname of project %s (%s) and will not be used", name, app.Name, strings.Join(app.GetHostnames(), ", "))
+		}
+	}
+
+	return nil
+}
+
+// PushCustomCerts copies the project's custom certificates to the router's
+// custom certificates directory on the ddev-global-cache volume and
+// regenerates its nginx config, whose template uses <hostname>.crt/.key for a
+// hostname when present and master.crt/.key otherwise. It also installs the
+// certificate for the primary hostname (or, failing that, any of them) as the
+// web container's certificate. Both are reloaded afterward.
+func (app *DdevApp) PushCustomCerts() error {
+	certs, err := app.GetCustomCerts()
+	if err != nil || len(certs) == 0 {
+		return err
+	}
+
+	hostnames := make([]string, 0, len(certs))
+	for hostname := range certs {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+
+	if !app.IsRouterDisabled() {
+		router, err := FindDdevRouter()
+		if err != nil {
+			return err
+		}
+		if out, err := exec.RunCommand("docker", []string{"exec", router.ID, "mkdir", "-p", routerCustomCertsDir}); err != nil {
+			return fmt.Errorf("failed to create %s in ddev-router: %v, output=%s", routerCustomCertsDir, err, out)
+		}
+		for _, hostname := range hostnames {
+			for _, ext := range []string{".crt", ".key"} {
+				err = dockerCopy(certs[hostname]+ext, router.ID+":"+routerCustomCertsDir+"/"+hostname+ext)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		// docker-gen only renders the config when containers change, so
+		// render it again now that the certificates are there.
+		regenerate := "docker-gen -only-exposed /app/nginx.tmpl /etc/nginx/conf.d/default.conf && nginx -s reload"
+		if out, err := exec.RunCommand("docker", []string{"exec", router.ID, "sh", "-c", regenerate}); err != nil {
+			return fmt.Errorf("failed to reload ddev-router with custom certificates: %v, output=%s", err, out)
+		}
+	}
+
+	base, ok := certs[app.GetHostname()]
+	if !ok {
+		base = certs[hostnames[0]]
+	}
+	web, err := app.FindContainerByType("web")
+	if err != nil {
+		return err
+	}
+	if web == nil {
+		return fmt.Errorf("unable to find web container to install custom certificates")
+	}
+	err = dockerCopy(base+".crt", web.ID+":/etc/ssl/certs/master.crt")
+	if err == nil {
+		err = dockerCopy(base+".key", web.ID+":/etc/ssl/certs/master.key")
+	}
+	if err != nil {
+		return err
+	}
+	reload := "nginx -s reload"
+	if app.GetWebserverType() != WebserverNginxFPM {
+		reload = "apache2ctl -k graceful"
+	}
+	if out, err := exec.RunCommand("docker", []string{"exec", "-u", "root", web.ID, "sh", "-c", reload}); err != nil {
+		return fmt.Errorf("failed to reload web server with custom certificates: %v, output=%s", err, out)
+	}
+
+	util.Success("Using custom certificates for %s", strings.Join(hostnames, ", "))
+	return nil
+}
+
+// RemoveCustomCerts removes the custom certificates of the project's
+// hostnames from the router's custom certificates directory, so they aren't
+// used for another project with the same hostnames. The router renders its
+// config again by itself once the project's containers are gone.
+func (app *DdevApp) RemoveCustomCerts() error {
+	if app.IsRouterDisabled() || !fileutil.FileExists(app.GetConfigPath(CustomCertsDir)) {
+		return nil
+	}
+	router, err := FindDdevRouter()
+	if err != nil {
+		// Without a router there's nothing to clean up in it.
+		return nil
+	}
+
+	rmArgs := []string{"exec", router.ID, "rm", "-f"}
+	for _, hostname := range app.GetHostnames() {
+		rmArgs = append(rmArgs, routerCustomCertsDir+"/"+hostname+".crt", routerCustomCertsDir+"/"+hostname+".key")
+	}
+	if out, err := exec.RunCommand("docker", rmArgs); err != nil {
+		return fmt.Errorf("failed to remove custom certificates from ddev-router: %v, output=%s", err, out)
+	}
+	return nil
+}
+
+// dockerCopy copies a file between the host and a container with "docker cp".
+func dockerCopy(src string, dest string) error {
+	out, err := exec.RunCommand("docker", []string{"cp", src, dest})
+	if err != nil {
+		return fmt.Errorf("docker cp %s %s failed: %v, output=%s", src, dest, err, out)
+	}
+	return nil
+}
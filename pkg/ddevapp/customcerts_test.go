@@ -0,0 +1,151 @@
+package ddevapp_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/exec"
+	"github.com/drud/ddev/pkg/testcommon"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCustomCerts makes sure custom certificates in .ddev/certs are found
+// for the project's hostnames and validated for hostname coverage.
+func TestCustomCerts(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	app.Name = "customcerts"
+	app.AdditionalHostnames = []string{"extra"}
+	err = app.WriteConfig()
+	require.NoError(t, err)
+
+	// No certs directory at all is fine.
+	certs, err := app.GetCustomCerts()
+	assert.NoError(err)
+	assert.Empty(certs)
+	assert.NoError(app.ValidateCustomCerts())
+
+	certsDir := app.GetConfigPath(ddevapp.CustomCertsDir)
+	err = os.MkdirAll(certsDir, 0755)
+	require.NoError(t, err)
+
+	// A certificate that covers the primary hostname is accepted.
+	writeTestCert(t, filepath.Join(certsDir, app.GetHostname()), []string{app.GetHostname()})
+	certs, err = app.GetCustomCerts()
+	assert.NoError(err)
+	assert.Equal(map[string]string{app.GetHostname(): filepath.Join(certsDir, app.GetHostname())}, certs)
+	assert.NoError(app.ValidateCustomCerts())
+
+	// A certificate named after a hostname it doesn't cover is rejected.
+	extra := "extra." + app.ProjectTLD
+	writeTestCert(t, filepath.Join(certsDir, extra), []string{"something-else.example.com"})
+	err = app.ValidateCustomCerts()
+	assert.Error(err)
+	assert.Contains(err.Error(), "does not cover "+extra)
+
+	// A wildcard certificate covers the additional hostname.
+	writeTestCert(t, filepath.Join(certsDir, extra), []string{"*." + app.ProjectTLD})
+	assert.NoError(app.ValidateCustomCerts())
+
+	// A certificate without its key is an error.
+	err = os.Remove(filepath.Join(certsDir, extra+".key"))
+	require.NoError(t, err)
+	_, err = app.GetCustomCerts()
+	assert.Error(err)
+	assert.Error(app.ValidateCustomCerts())
+}
+
+// TestCustomCertsRouter makes sure the router's generated nginx config uses a
+// custom certificate for its hostname, and master.crt for other hostnames and
+// the default server.
+func TestCustomCertsRouter(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+	testcommon.ClearDockerEnv()
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	app.Name = "customcertsrouter"
+	app.Type = ddevapp.AppTypePHP
+	app.AdditionalHostnames = []string{"customcertsrouter-extra"}
+	err = app.WriteConfig()
+	require.NoError(t, err)
+
+	certsDir := app.GetConfigPath(ddevapp.CustomCertsDir)
+	err = os.MkdirAll(certsDir, 0755)
+	require.NoError(t, err)
+	writeTestCert(t, filepath.Join(certsDir, app.GetHostname()), []string{app.GetHostname()})
+
+	err = app.Start()
+	// nolint: errcheck
+	defer app.Stop(true, false)
+	require.NoError(t, err)
+
+	config, err := exec.RunCommand("docker", []string{"exec", "ddev-router", "cat", "/etc/nginx/conf.d/default.conf"})
+	require.NoError(t, err)
+
+	// Find the certificate of each https server block by its server_name.
+	serverCerts := map[string][]string{}
+	serverName := ""
+	for _, line := range strings.Split(config, "\n") {
+		fields := strings.Fields(strings.TrimSuffix(strings.TrimSpace(line), ";"))
+		if len(fields) == 2 && fields[0] == "server_name" {
+			serverName = fields[1]
+		}
+		if len(fields) == 2 && fields[0] == "ssl_certificate" {
+			serverCerts[serverName] = append(serverCerts[serverName], fields[1])
+		}
+	}
+	assert.Contains(serverCerts[app.GetHostname()], "/mnt/ddev-global-cache/custom_certs/"+app.GetHostname()+".crt")
+	assert.NotContains(serverCerts[app.GetHostname()], "/etc/nginx/certs/master.crt")
+	extra := "customcertsrouter-extra." + app.ProjectTLD
+	assert.Contains(serverCerts[extra], "/etc/nginx/certs/master.crt")
+	assert.Equal([]string{"/etc/nginx/certs/master.crt"}, serverCerts["_"])
+}
+
+// writeTestCert writes a self-signed certificate for dnsNames and its key
+// to base.crt and base.key.
+func writeTestCert(t *testing.T, base string, dnsNames []string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{Organization: []string{"ddev test"}},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	err = ioutil.WriteFile(base+".crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(base+".key", pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	require.NoError(t, err)
+}
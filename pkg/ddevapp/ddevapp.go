@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"github.com/drud/ddev/pkg/globalconfig"
 	"github.com/drud/ddev/pkg/nodeps"
-	"github.com/lextoumbourou/goodhosts"
 	"github.com/mattn/go-isatty"
 	"github.com/mattn/go-shellwords"
 	"io/ioutil"
@@ -14,6 +13,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strconv"
+	"sync"
 
 	"golang.org/x/crypto/ssh/terminal"
 
@@ -34,7 +34,9 @@ import (
 	"github.com/drud/ddev/pkg/output"
 	"github.com/drud/ddev/pkg/util"
 	"github.com/drud/ddev/pkg/version"
+	"github.com/fatih/color"
 	"github.com/fsouza/go-dockerclient"
+	log "github.com/sirupsen/logrus"
 )
 
 // containerWaitTimeout is the max time we wait for all containers to become ready.
@@ -85,8 +87,12 @@ type DdevApp struct {
 	RouterHTTPPort        string               `yaml:"router_http_port"`
 	RouterHTTPSPort       string               `yaml:"router_https_port"`
 	XdebugEnabled         bool                 `yaml:"xdebug_enabled"`
+	Profiler              string               `yaml:"profiler,omitempty"`
+	NodeJSVersion         string               `yaml:"nodejs_version,omitempty"`
+	ComposerVersion       string               `yaml:"composer_version,omitempty"`
 	AdditionalHostnames   []string             `yaml:"additional_hostnames"`
 	AdditionalFQDNs       []string             `yaml:"additional_fqdns"`
+	DrupalMultisites      []DrupalMultisite    `yaml:"drupal_multisites,omitempty"`
 	MariaDBVersion        string               `yaml:"mariadb_version"`
 	WebcacheEnabled       bool                 `yaml:"webcache_enabled,omitempty"`
 	NFSMountEnabled       bool                 `yaml:"nfs_mount_enabled"`
@@ -108,10 +114,21 @@ type DdevApp struct {
 	MailhogPort           string               `yaml:"mailhog_port,omitempty"`
 	PHPMyAdminPort        string               `yaml:"phpmyadmin_port,omitempty"`
 	WebImageExtraPackages []string             `yaml:"webimage_extra_packages,omitempty,flow"`
+	PHPExtensions         []string             `yaml:"php_extensions,omitempty,flow"`
+	WebEnvironment        []string             `yaml:"web_environment,omitempty"`
+	DBEnvironment         []string             `yaml:"db_environment,omitempty"`
 	DBImageExtraPackages  []string             `yaml:"dbimage_extra_packages,omitempty,flow"`
 	ProjectTLD            string               `yaml:"project_tld,omitempty"`
 	UseDNSWhenPossible    bool                 `yaml:"use_dns_when_possible"`
 	MkcertEnabled         bool                 `yaml:"-"`
+
+	// customAppTypes are the project types defined in .ddev/apptypes.
+	customAppTypes map[string]*CustomAppType
+	// dockerEnv is the environment DockerEnv() set up for docker-compose.
+	dockerEnv map[string]string
+	// mariadbLocalCommand is the db container's command, only set to
+	// restore a snapshot on start.
+	mariadbLocalCommand string
 }
 
 // GetType returns the application type as a (lowercase) string
@@ -198,15 +215,31 @@ func (app *DdevApp) Describe() (map[string]interface{}, error) {
 		dbinfo["mariadb_version"] = app.MariaDBVersion
 		appDesc["dbinfo"] = dbinfo
 
-		appDesc["mailhog_url"] = "http://" + app.GetHostname() + ":" + app.MailhogPort
-		if !nodeps.ArrayContainsString(app.OmitContainers, "dba") {
-			appDesc["phpmyadmin_url"] = "http://" + app.GetHostname() + ":" + app.PHPMyAdminPort
+		if app.IsRouterDisabled() {
+			if url := app.getDirectServiceURL("web", appports.GetPort("mailhog")); url != "" {
+				appDesc["mailhog_url"] = url
+			}
+			if !nodeps.ArrayContainsString(app.OmitContainers, "dba") {
+				if url := app.getDirectServiceURL("dba", appports.GetPort("web")); url != "" {
+					appDesc["phpmyadmin_url"] = url
+				}
+			}
+		} else {
+			appDesc["mailhog_url"] = "http://" + app.GetHostname() + ":" + app.MailhogPort
+			if !nodeps.ArrayContainsString(app.OmitContainers, "dba") {
+				appDesc["phpmyadmin_url"] = "http://" + app.GetHostname() + ":" + app.PHPMyAdminPort
+			}
 		}
 	}
 
-	routerStatus, logOutput := GetRouterStatus()
-	appDesc["router_status"] = routerStatus
-	appDesc["router_status_log"] = logOutput
+	if app.IsRouterDisabled() {
+		appDesc["router_status"] = RouterDisabled
+		appDesc["router_status_log"] = ""
+	} else {
+		routerStatus, logOutput := GetRouterStatus()
+		appDesc["router_status"] = routerStatus
+		appDesc["router_status_log"] = logOutput
+	}
 	appDesc["ssh_agent_status"] = GetSSHAuthStatus()
 	appDesc["php_version"] = app.GetPhpVersion()
 	appDesc["webserver_type"] = app.GetWebserverType()
@@ -402,6 +435,22 @@ func (app *DdevApp) ExportDB(outFile string, gzip bool) error {
 	if gzip {
 		opts.Cmd = "mysqldump db | gzip"
 	}
+
+	// When the dump goes to stdout the hooks' output must not end up in it.
+	hookOut := output.UserOut
+	if outFile == "" {
+		hookOut = &log.Logger{
+			Out:       os.Stderr,
+			Formatter: output.UserOut.Formatter,
+			Hooks:     output.UserOut.Hooks,
+			Level:     output.UserOut.Level,
+		}
+	}
+
+	if err := app.processHooks("pre-export-db", hookOut); err != nil {
+		return err
+	}
+
 	if outFile != "" {
 		f, err := os.OpenFile(outFile, os.O_RDWR|os.O_CREATE, 0644)
 		if err != nil {
@@ -418,7 +467,7 @@ func (app *DdevApp) ExportDB(outFile string, gzip bool) error {
 		return err
 	}
 
-	return nil
+	return app.processHooks("post-export-db", hookOut)
 }
 
 // SiteStatus returns the current status of an application determined from web and db service health.
@@ -508,6 +557,10 @@ func (app *DdevApp) Pull(provider Provider, opts *PullOptions) error {
 		}
 	}
 
+	if err = app.ProcessHooks("pre-pull"); err != nil {
+		return err
+	}
+
 	if opts.SkipDb {
 		output.UserOut.Println("Skipping database pull.")
 	} else {
@@ -552,7 +605,7 @@ func (app *DdevApp) Pull(provider Provider, opts *PullOptions) error {
 		}
 	}
 
-	return nil
+	return app.ProcessHooks("post-pull")
 }
 
 // ImportFiles takes a source directory or archive and copies to the uploaded files directory of a given app.
@@ -621,59 +674,137 @@ func (app *DdevApp) ComposeFiles() ([]string, error) {
 
 // ProcessHooks executes commands defined in a Command
 func (app *DdevApp) ProcessHooks(hookName string) error {
+	return app.processHooks(hookName, output.UserOut)
+}
+
+// processHooks executes the commands of hookName like ProcessHooks, writing
+// their output to out.
+func (app *DdevApp) processHooks(hookName string, out *log.Logger) error {
 	if cmds := app.Commands[hookName]; len(cmds) > 0 {
-		output.UserOut.Printf("Executing %s commands...", hookName)
+		out.Printf("Executing %s commands...", hookName)
 	}
 
 	for _, c := range app.Commands[hookName] {
-		if c.Exec != "" {
-			output.UserOut.Printf("--- Running exec command: %s ---", c.Exec)
+		err := app.runHookTask(hookName, c, out)
+		if err == nil {
+			continue
+		}
+		if !c.ContinueOnError {
+			return err
+		}
+		out.Warnf("%v (continuing because of continue_on_error)", err)
+	}
 
-			testargs, err := shellwords.Parse(c.Exec)
-			if err != nil {
-				return fmt.Errorf("%s exec failed: %v", hookName, err)
-			}
-			_ = testargs
-			args := strings.Split(c.Exec, " ")
-			_ = args
+	return nil
+}
 
-			stdout, stderr, err := app.Exec(&ExecOpts{
-				Service: "web",
-				Cmd:     c.Exec,
-			})
+// runHookTask runs a single task of the hookName hook, writing its output to out.
+func (app *DdevApp) runHookTask(hookName string, c Command, out *log.Logger) error {
+	switch {
+	case c.Exec != "":
+		service := c.Service
+		if service == "" {
+			service = "web"
+		}
+		out.Printf("--- Running exec command in %s: %s ---", service, c.Exec)
 
-			if err != nil {
-				return fmt.Errorf("%s exec failed: %v, stderr='%s'", hookName, err, stderr)
-			}
-			util.Success("--- %s exec command succeeded, output below ---", hookName)
-			output.UserOut.Println(stdout + "\n" + stderr)
-		}
-		if c.ExecHost != "" {
-			output.UserOut.Printf("--- Running host command: %s ---", c.ExecHost)
-			args := strings.Split(c.ExecHost, " ")
-			cmd := args[0]
-			args = append(args[:0], args[1:]...)
-
-			// ensure exec-host runs from consistent location
-			cwd, err := os.Getwd()
-			util.CheckErr(err)
-			err = os.Chdir(app.GetAppRoot())
-			util.CheckErr(err)
-
-			out, err := exec.RunCommandPipe(cmd, args)
-			dirErr := os.Chdir(cwd)
-			util.CheckErr(dirErr)
-			output.UserOut.Println(out)
-			if err != nil {
-				return fmt.Errorf("%s host command failed: %v %s", hookName, err, out)
-			}
-			util.Success("--- %s host command succeeded ---\n", hookName)
+		if _, err := shellwords.Parse(c.Exec); err != nil {
+			return fmt.Errorf("%s exec failed: %v", hookName, err)
 		}
+
+		stdout, stderr, err := app.Exec(&ExecOpts{
+			Service: service,
+			Cmd:     c.Exec,
+		})
+		if err != nil {
+			return fmt.Errorf("%s exec failed: %v, stderr='%s'", hookName, err, stderr)
+		}
+		out.Infof(color.CyanString("--- %s exec command succeeded, output below ---"), hookName)
+		out.Println(stdout + "\n" + stderr)
+
+	case c.Composer != "":
+		out.Printf("--- Running composer command: %s ---", c.Composer)
+		stdout, stderr, err := app.Exec(&ExecOpts{
+			Service: "web",
+			Dir:     "/var/www/html",
+			Cmd:     "composer " + c.Composer,
+		})
+		if err != nil {
+			return fmt.Errorf("%s composer command failed: %v, stderr='%s'", hookName, err, stderr)
+		}
+		out.Infof(color.CyanString("--- %s composer command succeeded, output below ---"), hookName)
+		out.Println(stdout + "\n" + stderr)
+
+	case c.Drush != "":
+		out.Printf("--- Running drush command: %s ---", c.Drush)
+		stdout, stderr, err := app.Exec(&ExecOpts{
+			Service: "web",
+			Dir:     path.Join("/var/www/html", app.Docroot),
+			Cmd:     "drush " + c.Drush,
+		})
+		if err != nil {
+			return fmt.Errorf("%s drush command failed: %v, stderr='%s'", hookName, err, stderr)
+		}
+		out.Infof(color.CyanString("--- %s drush command succeeded, output below ---"), hookName)
+		out.Println(stdout + "\n" + stderr)
+
+	case c.ExecHost != "":
+		out.Printf("--- Running host command: %s ---", c.ExecHost)
+		hostOut, err := app.hostHookCommand(c.ExecHost).CombinedOutput()
+		out.Println(string(hostOut))
+		if err != nil {
+			return fmt.Errorf("%s host command failed: %v %s", hookName, err, hostOut)
+		}
+		out.Infof(color.CyanString("--- %s host command succeeded ---\n"), hookName)
 	}
 
 	return nil
 }
 
+// hostHookCommand returns the command running an exec-host task through the
+// host's shell in the project root, so quoting, pipes and variables work as
+// they would in a terminal.
+func (app *DdevApp) hostHookCommand(command string) *osexec.Cmd {
+	cmd := osexec.Command("sh", "-c", command)
+	if runtime.GOOS == "windows" {
+		cmd = osexec.Command("cmd", "/C", command)
+	}
+	cmd.Dir = app.GetAppRoot()
+	cmd.Env = app.hostHookEnv()
+	return cmd
+}
+
+// hostHookEnv returns the environment for exec-host tasks: ddev's environment
+// with the project's DDEV_* variables, its URLs, the published db port and
+// the web container's environment from web_environment and .ddev/project.env.
+func (app *DdevApp) hostHookEnv() []string {
+	app.DockerEnv()
+	env := app.composeEnv()
+	webEnvironment, err := app.GetWebEnvironment()
+	if err != nil {
+		util.Warning("Unable to read the web environment: %v", err)
+	}
+	env = append(env, webEnvironment...)
+	// Without the router the URLs aren't known before the web container
+	// is running, so they're left unset rather than set to nothing useful.
+	for _, v := range [][2]string{
+		{"DDEV_PRIMARY_URL", app.GetHTTPSURL()},
+		{"DDEV_HTTP_URL", app.GetHTTPURL()},
+		{"DDEV_HTTPS_URL", app.GetHTTPSURL()},
+		{"DDEV_URLS", strings.Join(app.GetAllURLs(), ",")},
+	} {
+		if v[1] != "" {
+			env = append(env, v[0]+"="+v[1])
+		}
+	}
+	if app.SiteStatus() == SiteRunning {
+		if port, err := app.GetPublishedPort("db"); err == nil {
+			env = append(env, "DDEV_HOST_DB_PORT="+strconv.Itoa(port))
+		}
+	}
+	return env
+}
+
 // Start initiates docker-compose up
 func (app *DdevApp) Start() error {
 	var err error
@@ -706,10 +837,19 @@ func (app *DdevApp) Start() error {
 	// Warn the user if there is any custom configuration in use.
 	app.CheckCustomConfig()
 
+	// Fail early if custom certificates don't cover their hostnames.
+	err = app.ValidateCustomCerts()
+	if err != nil {
+		return err
+	}
+
+	app.CheckProfilerCredentials()
+
 	caRoot := GetCAROOT()
 	if caRoot == "" {
 		util.Warning("mkcert may not be properly installed, please install it, `brew install mkcert nss`, `choco install -y mkcert`, etc. and then `mkcert -install`: %v", err)
 	}
+	routerLock.Lock()
 	router, _ := FindDdevRouter()
 	// If the router doesn't exist, go ahead and push mkcert root ca certs into the ddev-global-cache/mkcert
 	// This will often be redundant
@@ -724,18 +864,28 @@ func (app *DdevApp) Start() error {
 			util.Success("Pushed mkcert rootca certs to ddev-global-cache")
 		}
 	}
+	routerLock.Unlock()
 
-	// WriteConfig docker-compose.yaml
-	err = app.WriteDockerComposeConfig()
+	err = app.UseFallbackRouterPorts()
 	if err != nil {
 		return err
 	}
 
-	err = app.AddHostsEntriesIfNeeded()
+	// WriteConfig docker-compose.yaml
+	err = app.WriteDockerComposeConfig()
 	if err != nil {
 		return err
 	}
 
+	// Without the router the project is only reached by IP and published port,
+	// so hostnames are of no use.
+	if !app.IsRouterDisabled() {
+		err = app.AddHostsEntriesIfNeeded()
+		if err != nil {
+			return err
+		}
+	}
+
 	files, err := app.ComposeFiles()
 	if err != nil {
 		return err
@@ -746,14 +896,16 @@ func (app *DdevApp) Start() error {
 	_ = dockerutil.RemoveVolume(app.GetWebcacheVolName())
 	_ = dockerutil.RemoveVolume(app.GetNFSMountVolName())
 
-	_, _, err = dockerutil.ComposeCmd(files, "up", "--build", "-d")
+	_, _, err = dockerutil.ComposeCmdEnv(app.composeEnv(), files, "up", "--build", "-d")
 	if err != nil {
 		return err
 	}
 
-	err = StartDdevRouter()
-	if err != nil {
-		return err
+	if !app.IsRouterDisabled() {
+		err = StartDdevRouter()
+		if err != nil {
+			return err
+		}
 	}
 
 	requiredContainers := []string{"db", "web"}
@@ -770,6 +922,16 @@ func (app *DdevApp) Start() error {
 		return err
 	}
 
+	err = app.PushCustomCerts()
+	if err != nil {
+		return err
+	}
+
+	err = app.verifyPHPExtensions()
+	if err != nil {
+		util.Warning("%v", err)
+	}
+
 	err = app.PostStartAction()
 	if err != nil {
 		return err
@@ -808,6 +970,8 @@ type ExecOpts struct {
 	Stdout *os.File
 	// Stderr can be overridden with a File
 	Stderr *os.File
+	// User is the user (name or uid) to run the command as, like "root"
+	User string
 }
 
 // Exec executes a given command in the container of given type without allocating a pty
@@ -829,6 +993,10 @@ func (app *DdevApp) Exec(opts *ExecOpts) (string, string, error) {
 		exec = append(exec, "-T")
 	}
 
+	if opts.User != "" {
+		exec = append(exec, "--user", opts.User)
+	}
+
 	exec = append(exec, opts.Service)
 
 	if opts.Cmd == "" {
@@ -864,9 +1032,9 @@ func (app *DdevApp) Exec(opts *ExecOpts) (string, string, error) {
 
 	var stdoutResult, stderrResult string
 	if opts.NoCapture || opts.Tty {
-		err = dockerutil.ComposeWithStreams(files, os.Stdin, stdout, stderr, exec...)
+		err = dockerutil.ComposeWithStreamsEnv(app.composeEnv(), files, os.Stdin, stdout, stderr, exec...)
 	} else {
-		stdoutResult, stderrResult, err = dockerutil.ComposeCmd(files, exec...)
+		stdoutResult, stderrResult, err = dockerutil.ComposeCmdEnv(app.composeEnv(), files, exec...)
 	}
 
 	return stdoutResult, stderrResult, err
@@ -899,7 +1067,7 @@ func (app *DdevApp) ExecWithTty(opts *ExecOpts) error {
 		return err
 	}
 
-	return dockerutil.ComposeWithStreams(files, os.Stdin, os.Stdout, os.Stderr, exec...)
+	return dockerutil.ComposeWithStreamsEnv(app.composeEnv(), files, os.Stdin, os.Stdout, os.Stderr, exec...)
 }
 
 // Logs returns logs for a site's given container.
@@ -1024,13 +1192,8 @@ func (app *DdevApp) DockerEnv() {
 		"DDEV_ROUTER_HTTP_PORT":         app.RouterHTTPPort,
 		"DDEV_ROUTER_HTTPS_PORT":        app.RouterHTTPSPort,
 		"DDEV_XDEBUG_ENABLED":           strconv.FormatBool(app.XdebugEnabled),
-	}
-
-	// Set the mariadb_local command to empty to prevent docker-compose from complaining normally.
-	// It's used for special startup on restoring to a snapshot.
-	if len(os.Getenv("DDEV_MARIADB_LOCAL_COMMAND")) == 0 {
-		err := os.Setenv("DDEV_MARIADB_LOCAL_COMMAND", "")
-		util.CheckErr(err)
+		// Normally empty; it's used for special startup on restoring to a snapshot.
+		"DDEV_MARIADB_LOCAL_COMMAND": app.mariadbLocalCommand,
 	}
 
 	// Find out terminal dimensions
@@ -1042,16 +1205,36 @@ func (app *DdevApp) DockerEnv() {
 	envVars["COLUMNS"] = strconv.Itoa(columns)
 	envVars["LINES"] = strconv.Itoa(lines)
 
-	if len(app.AdditionalHostnames) > 0 || len(app.AdditionalFQDNs) > 0 {
+	if len(app.AdditionalHostnames) > 0 || len(app.AdditionalFQDNs) > 0 || len(app.DrupalMultisites) > 0 {
 		envVars["DDEV_HOSTNAME"] = strings.Join(app.GetHostnames(), ",")
 	}
 
-	// Only set values if they don't already exist in env.
-	for k, v := range envVars {
-		if err := os.Setenv(k, v); err != nil {
-			util.Error("Failed to set the environment variable %s=%s: %v", k, v, err)
-		}
+	// These only go to the project's own docker-compose commands through
+	// composeEnv(), since the process environment is shared by all the
+	// projects handled at the same time.
+	app.dockerEnv = envVars
+}
+
+// composeEnv returns the environment for the project's docker-compose
+// commands: ddev's environment with the project's DockerEnv() values.
+func (app *DdevApp) composeEnv() []string {
+	env := os.Environ()
+	for k, v := range app.dockerEnv {
+		env = append(env, k+"="+v)
+	}
+	return env
+}
+
+// ComposeConfig returns the project's docker-compose configuration, as
+// "docker-compose config" renders it with the project's environment.
+func (app *DdevApp) ComposeConfig() (string, error) {
+	app.DockerEnv()
+	files, err := app.ComposeFiles()
+	if err != nil {
+		return "", err
 	}
+	out, _, err := dockerutil.ComposeCmdEnv(app.composeEnv(), files, "config")
+	return out, err
 }
 
 // Pause initiates docker-compose stop
@@ -1067,7 +1250,7 @@ func (app *DdevApp) Pause() error {
 		return err
 	}
 
-	if _, _, err := dockerutil.ComposeCmd(files, "stop"); err != nil {
+	if _, _, err := dockerutil.ComposeCmdEnv(app.composeEnv(), files, "stop"); err != nil {
 		return err
 	}
 
@@ -1189,6 +1372,10 @@ func (app *DdevApp) SnapshotDatabase(snapshotName string) (string, error) {
 		return "", fmt.Errorf("unable to snapshot database, \nyour project %v is not running. \nPlease start the project if you want to snapshot it. \nIf removing, you can remove without a snapshot using \n'ddev stop --remove-data --omit-snapshot', \nwhich will destroy your database", app.Name)
 	}
 
+	if err = app.ProcessHooks("pre-snapshot"); err != nil {
+		return "", err
+	}
+
 	util.Warning("Creating database snapshot %s", snapshotName)
 	stdout, stderr, err := app.Exec(&ExecOpts{
 		Service: "db",
@@ -1200,6 +1387,10 @@ func (app *DdevApp) SnapshotDatabase(snapshotName string) (string, error) {
 		return "", err
 	}
 	util.Success("Created database snapshot %s in %s", snapshotName, hostSnapshotDir)
+
+	if err = app.ProcessHooks("post-snapshot"); err != nil {
+		return snapshotName, err
+	}
 	return snapshotName, nil
 }
 
@@ -1236,6 +1427,10 @@ func (app *DdevApp) RestoreSnapshot(snapshotName string) error {
 		return fmt.Errorf("snapshot %s is a MariaDB %s snapshot\nIt is not compatible with the configured ddev MariaDB version (%s).", snapshotDir, snapshotMariaDBVersion, app.MariaDBVersion)
 	}
 
+	if err = app.ProcessHooks("pre-restore-snapshot"); err != nil {
+		return err
+	}
+
 	if app.SiteStatus() == SiteRunning || app.SiteStatus() == SitePaused {
 		err := app.Stop(false, false)
 		if err != nil {
@@ -1243,17 +1438,15 @@ func (app *DdevApp) RestoreSnapshot(snapshotName string) error {
 		}
 	}
 
-	err = os.Setenv("DDEV_MARIADB_LOCAL_COMMAND", "restore_snapshot "+snapshotName)
-	util.CheckErr(err)
+	app.mariadbLocalCommand = "restore_snapshot " + snapshotName
 	err = app.Start()
+	app.mariadbLocalCommand = ""
 	if err != nil {
 		return fmt.Errorf("Failed to start project for RestoreSnapshot: %v", err)
 	}
-	err = os.Unsetenv("DDEV_MARIADB_LOCAL_COMMAND")
-	util.CheckErr(err)
 
 	util.Success("Restored database snapshot: %s", hostSnapshotDir)
-	return nil
+	return app.ProcessHooks("post-restore-snapshot")
 }
 
 // Stops and Removes the docker containers for the project in current directory.
@@ -1262,6 +1455,13 @@ func (app *DdevApp) Stop(removeData bool, createSnapshot bool) error {
 
 	var err error
 
+	// The pre-stop hook needs the containers, so it only runs if they're up.
+	if app.SiteStatus() == SiteRunning {
+		if err = app.ProcessHooks("pre-stop"); err != nil {
+			util.Warning("Failed to run pre-stop hook of %s, continuing: %v", app.GetName(), err)
+		}
+	}
+
 	if createSnapshot == true {
 		t := time.Now()
 		_, err = app.SnapshotDatabase(app.Name + "_remove_data_snapshot_" + t.Format("20060102150405"))
@@ -1270,6 +1470,10 @@ func (app *DdevApp) Stop(removeData bool, createSnapshot bool) error {
 		}
 	}
 
+	if err = app.RemoveCustomCerts(); err != nil {
+		util.Warning("Failed to remove custom certificates of %s: %v", app.GetName(), err)
+	}
+
 	err = app.Pause()
 	if err != nil {
 		util.Warning("Failed to stop containers for %s: %v", app.GetName(), err)
@@ -1285,8 +1489,9 @@ func (app *DdevApp) Stop(removeData bool, createSnapshot bool) error {
 		if err = app.RemoveHostsEntries(); err != nil {
 			return fmt.Errorf("failed to remove hosts entries: %v", err)
 		}
-		app.RemoveGlobalProjectInfo()
-		err = globalconfig.WriteGlobalConfig(globalconfig.DdevGlobalConfig)
+		err = globalconfig.UpdateGlobalConfig(func(config *globalconfig.GlobalConfig) {
+			delete(config.ProjectList, app.Name)
+		})
 		if err != nil {
 			util.Warning("could not WriteGlobalConfig: %v", err)
 		}
@@ -1309,8 +1514,33 @@ func (app *DdevApp) RemoveGlobalProjectInfo() {
 	_ = globalconfig.RemoveProjectInfo(app.Name)
 }
 
-// GetHTTPURL returns the HTTP URL for an app.
+// IsRouterDisabled returns true if ddev-router is omitted, either in the project's
+// omit_containers or in the global omit_containers. The router is shared by all
+// projects, so omitting it globally always wins.
+func (app *DdevApp) IsRouterDisabled() bool {
+	return nodeps.ArrayContainsString(app.OmitContainers, RouterContainer) || nodeps.ArrayContainsString(globalconfig.DdevGlobalConfig.OmitContainers, RouterContainer)
+}
+
+// GetHTTPURL returns the HTTP URL for an app. Without the router it's ""
+// until the web container has published its port.
 func (app *DdevApp) GetHTTPURL() string {
+	if app.IsRouterDisabled() {
+		return app.GetWebContainerDirectURL()
+	}
+	return app.getRouterHTTPURL()
+}
+
+// GetHTTPSURL returns the HTTPS URL for an app. Without the router it's ""
+// until the web container has published its port.
+func (app *DdevApp) GetHTTPSURL() string {
+	if app.IsRouterDisabled() {
+		return app.GetWebContainerDirectHTTPSURL()
+	}
+	return app.getRouterHTTPSURL()
+}
+
+// getRouterHTTPURL returns the HTTP URL of the app's hostname through the router.
+func (app *DdevApp) getRouterHTTPURL() string {
 	url := "http://" + app.GetHostname()
 	if app.RouterHTTPPort != "80" {
 		url = url + ":" + app.RouterHTTPPort
@@ -1318,8 +1548,8 @@ func (app *DdevApp) GetHTTPURL() string {
 	return url
 }
 
-// GetHTTPSURL returns the HTTPS URL for an app.
-func (app *DdevApp) GetHTTPSURL() string {
+// getRouterHTTPSURL returns the HTTPS URL of the app's hostname through the router.
+func (app *DdevApp) getRouterHTTPSURL() string {
 	url := "https://" + app.GetHostname()
 	if app.RouterHTTPSPort != "443" {
 		url = url + ":" + app.RouterHTTPSPort
@@ -1327,10 +1557,34 @@ func (app *DdevApp) GetHTTPSURL() string {
 	return url
 }
 
-// GetAllURLs returns an array of all the URLs for the project
+// getSettingsURL returns the URL written into the settings of CMSs, the
+// HTTPS URL if https is set and the HTTP URL otherwise. Without the router
+// the URL is unknown until the web container has published its port, so
+// the router URL is used until then.
+func (app *DdevApp) getSettingsURL(https bool) string {
+	if https {
+		if url := app.GetHTTPSURL(); url != "" {
+			return url
+		}
+		return app.getRouterHTTPSURL()
+	}
+	if url := app.GetHTTPURL(); url != "" {
+		return url
+	}
+	return app.getRouterHTTPURL()
+}
+
+// GetAllURLs returns an array of all the URLs for the project. Without the
+// router it's empty until the web container has published its ports.
 func (app *DdevApp) GetAllURLs() []string {
 	var URLs []string
 
+	// Without the router, the hostnames don't lead anywhere, so only
+	// the direct web container URLs are useful.
+	if app.IsRouterDisabled() {
+		return app.getDirectURLs()
+	}
+
 	// Get configured URLs
 	for _, name := range app.GetHostnames() {
 		httpPort := ""
@@ -1349,35 +1603,75 @@ func (app *DdevApp) GetAllURLs() []string {
 		URLs = append(URLs, url)
 	}
 
+	directURL := app.GetWebContainerDirectURL()
 	if GetCAROOT() != "" {
-		URLs = append(URLs, app.GetWebContainerDirectHTTPSURL())
-	} else {
-		URLs = append(URLs, app.GetWebContainerDirectURL())
+		directURL = app.GetWebContainerDirectHTTPSURL()
+	}
+	if directURL != "" {
+		URLs = append(URLs, directURL)
 	}
 
 	return URLs
 }
 
+// getDirectURLs returns the URLs of the web container's published ports,
+// the HTTPS one first if there's a CA to trust it.
+func (app *DdevApp) getDirectURLs() []string {
+	var URLs []string
+	if GetCAROOT() != "" {
+		if url := app.GetWebContainerDirectHTTPSURL(); url != "" {
+			URLs = append(URLs, url)
+		}
+	}
+	if url := app.GetWebContainerDirectURL(); url != "" {
+		URLs = append(URLs, url)
+	}
+	return URLs
+}
+
 // GetWebContainerDirectURL returns the URL that can be used without the router to get to web container.
+// It's "" if the web container isn't running and no host_webserver_port is configured.
 func (app *DdevApp) GetWebContainerDirectURL() string {
-	// Get direct address of web container
-	dockerIP, err := dockerutil.GetDockerIP()
+	port, err := app.GetWebContainerPublicPort()
 	if err != nil {
-		util.Warning("Unable to get Docker IP: %v", err)
+		port, _ = strconv.Atoi(app.HostWebserverPort)
 	}
-	port, _ := app.GetWebContainerPublicPort()
-	return fmt.Sprintf("http://%s:%d", dockerIP, port)
+	return app.directURL("http", port)
 }
 
-// GetWebContainerDirectURL returns the URL that can be used without the router to get to web container.
+// GetWebContainerDirectHTTPSURL returns the https URL that can be used without the router to get to web container.
+// It's "" if the web container isn't running and no host_https_port is configured.
 func (app *DdevApp) GetWebContainerDirectHTTPSURL() string {
-	// Get direct address of web container
+	port, err := app.GetWebContainerHTTPSPublicPort()
+	if err != nil {
+		port, _ = strconv.Atoi(app.HostHTTPSPort)
+	}
+	return app.directURL("https", port)
+}
+
+// directURL returns the URL of a port published on the docker IP, or "" if
+// there's no such port.
+func (app *DdevApp) directURL(scheme string, port int) string {
+	if port <= 0 {
+		return ""
+	}
 	dockerIP, err := dockerutil.GetDockerIP()
 	if err != nil {
 		util.Warning("Unable to get Docker IP: %v", err)
+		return ""
+	}
+	return fmt.Sprintf("%s://%s:%d", scheme, dockerIP, port)
+}
+
+// getDirectServiceURL returns the http URL of a container port published
+// directly on the docker IP, bypassing the router.
+func (app *DdevApp) getDirectServiceURL(service string, privatePort string) string {
+	container, err := app.FindContainerByType(service)
+	if err != nil || container == nil {
+		return ""
 	}
-	port, _ := app.GetWebContainerHTTPSPublicPort()
-	return fmt.Sprintf("https://%s:%d", dockerIP, port)
+	port, _ := strconv.ParseInt(privatePort, 10, 16)
+	return app.directURL("http", dockerutil.GetPublishedPort(port, *container))
 }
 
 // GetWebContainerPublicPort returns the direct-access public tcp port for http
@@ -1417,8 +1711,15 @@ func (app *DdevApp) HostName() string {
 	return app.GetHostname()
 }
 
+// hostsLock serializes changes to the hosts file when several projects are
+// started or stopped at the same time.
+var hostsLock sync.Mutex
+
 // AddHostsEntriesIfNeeded will (optionally) add the site URL to the host's /etc/hosts.
 func (app *DdevApp) AddHostsEntriesIfNeeded() error {
+	hostsLock.Lock()
+	defer hostsLock.Unlock()
+
 	dockerIP, err := dockerutil.GetDockerIP()
 	if err != nil {
 		return fmt.Errorf("could not get Docker IP: %v", err)
@@ -1439,6 +1740,7 @@ func (app *DdevApp) AddHostsEntriesIfNeeded() error {
 		}
 	}
 
+	var missing []string
 	for _, name := range app.GetHostnames() {
 		if app.UseDNSWhenPossible {
 			hostIPs, err := net.LookupHost(name)
@@ -1455,33 +1757,61 @@ func (app *DdevApp) AddHostsEntriesIfNeeded() error {
 		if hosts.Has(dockerIP, name) {
 			continue
 		}
-		err = addHostEntry(name, dockerIP)
-		if err != nil {
-			return err
-		}
+		missing = append(missing, name)
+	}
+	if len(missing) == 0 {
+		return nil
 	}
 
-	return nil
+	return addHostEntries(hosts, missing, dockerIP)
+}
+
+// addHostEntries adds all of the names to the ddev-managed section of the hosts file
+// with a single 'ddev hostname' invocation.
+func addHostEntries(hosts *ddevhosts.DdevHosts, names []string, ip string) error {
+	hostnameArgs := append([]string{"hostname"}, names...)
+	hostnameArgs = append(hostnameArgs, ip)
+	manual := fmt.Sprintf("You must manually add the following entries to your hosts file:\n%s %s\nOr with root/administrative privileges execute 'ddev hostname %s %s'", ip, strings.Join(names, " "), strings.Join(names, " "), ip)
+	return runHostnameCommand(hosts, hostnameArgs, "add entries to", manual)
 }
 
-func addHostEntry(name string, ip string) error {
-	_, err := osexec.LookPath("sudo")
-	if (os.Getenv("DRUD_NONINTERACTIVE") != "") || err != nil {
-		util.Warning("You must manually add the following entry to your hosts file:\n%s %s\nOr with root/administrative privileges execute 'ddev hostname %s %s'", ip, name, name, ip)
+// runHostnameCommand runs 'ddev hostname' with hostnameArgs to change the hosts file.
+// If the hosts file is writable it's run directly, otherwise it's run with sudo,
+// telling it the path of the hosts file explicitly since sudo doesn't keep
+// $DDEV_HOSTS_FILE. If sudo can't be used, the manual instructions are shown instead.
+func runHostnameCommand(hosts *ddevhosts.DdevHosts, hostnameArgs []string, action string, manual string) error {
+	ddevFullPath, err := os.Executable()
+	util.CheckErr(err)
+
+	if os.Getenv(ddevhosts.HostsFileEnv) != "" {
+		hostnameArgs = append([]string{hostnameArgs[0], "--hosts-file", hosts.Path}, hostnameArgs[1:]...)
+	}
+
+	if os.Getenv("DRUD_NONINTERACTIVE") != "" {
+		util.Warning("%s", manual)
 		return nil
 	}
 
-	ddevFullpath, err := os.Executable()
-	util.CheckErr(err)
+	if hosts.IsWritable() {
+		_, err = exec.RunCommandPipe(ddevFullPath, hostnameArgs)
+		if err != nil {
+			util.Warning("Failed to execute '%s %s': %v", ddevFullPath, strings.Join(hostnameArgs, " "), err)
+		}
+		return nil
+	}
 
-	output.UserOut.Printf("ddev needs to add an entry to your hostfile.\nIt will require administrative privileges via the sudo command, so you may be required\nto enter your password for sudo. ddev is about to issue the command:")
+	if _, err = osexec.LookPath("sudo"); err != nil {
+		util.Warning("%s", manual)
+		return nil
+	}
+
+	output.UserOut.Printf("ddev needs to %s your hosts file.\nIt will require administrative privileges via the sudo command, so you may be required\nto enter your password for sudo. ddev is about to issue the command:", action)
 
-	hostnameArgs := []string{ddevFullpath, "hostname", name, ip}
-	command := strings.Join(hostnameArgs, " ")
+	sudoArgs := append([]string{ddevFullPath}, hostnameArgs...)
+	command := strings.Join(sudoArgs, " ")
 	util.Warning(fmt.Sprintf("    sudo %s", command))
 	output.UserOut.Println("Please enter your password if prompted.")
-	_, err = exec.RunCommandPipe("sudo", hostnameArgs)
-	if err != nil {
+	if _, err = exec.RunCommandPipe("sudo", sudoArgs); err != nil {
 		util.Warning("Failed to execute sudo command, you will need to manually execute '%s' with administrative privileges", command)
 	}
 	return nil
@@ -1489,43 +1819,34 @@ func addHostEntry(name string, ip string) error {
 
 // RemoveHostsEntries will remote the site URL from the host's /etc/hosts.
 func (app *DdevApp) RemoveHostsEntries() error {
+	hostsLock.Lock()
+	defer hostsLock.Unlock()
+
 	dockerIP, err := dockerutil.GetDockerIP()
 	if err != nil {
 		return fmt.Errorf("could not get Docker IP: %v", err)
 	}
 
-	hosts, err := goodhosts.NewHosts()
+	hosts, err := ddevhosts.New()
 	if err != nil {
 		util.Failed("could not open hostfile: %v", err)
 	}
 
+	var present []string
 	for _, name := range app.GetHostnames() {
-		if !hosts.Has(dockerIP, name) {
-			continue
-		}
-
-		_, err = osexec.LookPath("sudo")
-		if os.Getenv("DRUD_NONINTERACTIVE") != "" || err != nil {
-			util.Warning("You must manually remove the following entry from your hosts file:\n%s %s\nOr with root/administrative privileges execute 'ddev hostname --remove %s %s", dockerIP, name, name, dockerIP)
-			return nil
-		}
-
-		ddevFullPath, err := os.Executable()
-		util.CheckErr(err)
-
-		output.UserOut.Printf("ddev needs to remove an entry from your hosts file.\nIt will require administrative privileges via the sudo command, so you may be required\nto enter your password for sudo. ddev is about to issue the command:")
-
-		hostnameArgs := []string{ddevFullPath, "hostname", "--remove", name, dockerIP}
-		command := strings.Join(hostnameArgs, " ")
-		util.Warning(fmt.Sprintf("    sudo %s", command))
-		output.UserOut.Println("Please enter your password if prompted.")
-
-		if _, err = exec.RunCommandPipe("sudo", hostnameArgs); err != nil {
-			util.Warning("Failed to execute sudo command, you will need to manually execute '%s' with administrative privileges", command)
+		if hosts.Has(dockerIP, name) {
+			present = append(present, name)
 		}
 	}
+	if len(present) == 0 {
+		return nil
+	}
+	names := strings.Join(present, " ")
 
-	return nil
+	hostnameArgs := append([]string{"hostname", "--remove"}, present...)
+	hostnameArgs = append(hostnameArgs, dockerIP)
+	manual := fmt.Sprintf("You must manually remove the following entries from your hosts file:\n%s %s\nOr with root/administrative privileges execute 'ddev hostname --remove %s %s'", dockerIP, names, names, dockerIP)
+	return runHostnameCommand(hosts, hostnameArgs, "remove entries from", manual)
 }
 
 // GetActiveAppRoot returns the fully rooted directory of the active app, or an error
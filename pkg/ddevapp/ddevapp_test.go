@@ -1473,7 +1473,7 @@ func TestProcessHooks(t *testing.T) {
 	out := vtclean.Clean(stdout(), false)
 
 	assert.Contains(out, "hook-test exec command succeeded, output below ---\n/usr/local/bin/composer")
-	assert.Contains(out, "--- Running host command: echo something ---\nRunning Command Command=echo something\nsomething")
+	assert.Contains(out, "--- Running host command: echo something ---\nsomething")
 	assert.FileExists(filepath.Join(app.AppRoot, fmt.Sprintf("TestProcessHooks%s.txt", app.RouterHTTPSPort)))
 	assert.FileExists(filepath.Join(app.AppRoot, "touch_works_after_and.txt"))
 
@@ -1484,6 +1484,52 @@ func TestProcessHooks(t *testing.T) {
 	cleanup()
 }
 
+// TestProcessHostHooks tests that exec-host tasks run through the shell in the
+// project root, with the project's DDEV_* variables, and leave the cwd alone.
+func TestProcessHostHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping because the test uses a POSIX shell")
+	}
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+
+	cwd, err := os.Getwd()
+	require.NoError(t, err)
+
+	app.Commands = map[string][]ddevapp.Command{
+		"hook-test": {
+			{ExecHost: `printf '%s\n' "a b" "$DDEV_SITENAME" "$(pwd)" | tee hook.txt`},
+			{ExecHost: "exit 3", ContinueOnError: true},
+		},
+	}
+	err = app.ProcessHooks("hook-test")
+	assert.NoError(err)
+
+	out, err := ioutil.ReadFile(filepath.Join(testDir, "hook.txt"))
+	require.NoError(t, err)
+	appRoot, err := filepath.EvalSymlinks(testDir)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal("a b", lines[0])
+	assert.Equal(app.Name, lines[1])
+	resolved, err := filepath.EvalSymlinks(lines[2])
+	require.NoError(t, err)
+	assert.Equal(appRoot, resolved)
+
+	newCwd, err := os.Getwd()
+	require.NoError(t, err)
+	assert.Equal(cwd, newCwd)
+
+	app.Commands["hook-test"][1].ContinueOnError = false
+	err = app.ProcessHooks("hook-test")
+	assert.Error(err)
+}
+
 // TestDdevStop tests the functionality that is called when "ddev stop" is executed
 func TestDdevStop(t *testing.T) {
 	assert := asrt.New(t)
@@ -0,0 +1,160 @@
+package ddevapp
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/drud/ddev/pkg/fileutil"
+)
+
+// DetectionMatch is a project type whose detection matched with a docroot.
+type DetectionMatch struct {
+	AppType  string   `json:"type"`
+	Docroot  string   `json:"docroot"`
+	Evidence []string `json:"evidence"`
+}
+
+// DocrootCandidate is a possible docroot of the project.
+type DocrootCandidate struct {
+	Docroot  string   `json:"docroot"`
+	Evidence []string `json:"evidence"`
+}
+
+// DetectionReport is everything the project type and docroot detection found.
+type DetectionReport struct {
+	// AppType and Docroot are what 'ddev config' would pick.
+	AppType  string             `json:"detected_type"`
+	Docroot  string             `json:"detected_docroot"`
+	Matches  []DetectionMatch   `json:"type_matches"`
+	Docroots []DocrootCandidate `json:"docroot_candidates"`
+	Warnings []string           `json:"warnings"`
+}
+
+// DetectionReport runs every project type detector against the project root
+// and each docroot candidate, and reports every match with the files that
+// caused it, warning where the result is ambiguous.
+func (app *DdevApp) DetectionReport() *DetectionReport {
+	report := &DetectionReport{
+		Matches:  []DetectionMatch{},
+		Docroots: []DocrootCandidate{},
+		Warnings: []string{},
+	}
+
+	// The detectors look at app.Docroot, so try each candidate in turn on a copy.
+	probe := *app
+	probe.Docroot = ""
+	report.Docroot = DiscoverDefaultDocroot(&probe)
+	if app.Docroot != "" {
+		report.Docroot = app.Docroot
+	}
+
+	docroots := []string{""}
+	if app.Docroot != "" {
+		docroots = append(docroots, app.Docroot)
+	}
+	for _, d := range AvailableDocrootLocations() {
+		if d != app.Docroot {
+			docroots = append(docroots, d)
+		}
+	}
+
+	for _, docroot := range docroots {
+		if docroot != "" && !fileutil.FileExists(filepath.Join(app.AppRoot, docroot)) {
+			continue
+		}
+		if index := filepath.Join(docroot, "index.php"); fileutil.FileExists(filepath.Join(app.AppRoot, index)) {
+			report.Docroots = append(report.Docroots, DocrootCandidate{Docroot: docroot, Evidence: []string{index}})
+		}
+
+		probe.Docroot = docroot
+		for _, appType := range app.GetValidProjectTypes() {
+			probe.Type = appType
+			appFuncs, ok := probe.getAppTypeFuncs()
+			if !ok || appFuncs.appTypeDetect == nil {
+				continue
+			}
+			evidence := appFuncs.appTypeDetect(&probe)
+			if len(evidence) == 0 {
+				continue
+			}
+			// Detectors that look at the project root rather than the
+			// docroot match with any docroot; report them just once.
+			if docroot != "" && !inDir(docroot, evidence) {
+				continue
+			}
+			report.Matches = append(report.Matches, DetectionMatch{AppType: appType, Docroot: docroot, Evidence: evidence})
+		}
+	}
+
+	probe.Docroot = report.Docroot
+	report.AppType = probe.DetectAppType()
+
+	types := map[string]bool{}
+	for _, m := range report.Matches {
+		types[m.AppType] = true
+	}
+	if len(types) > 1 {
+		var names []string
+		for t := range types {
+			names = append(names, t)
+		}
+		sort.Strings(names)
+		report.Warnings = append(report.Warnings, fmt.Sprintf("More than one project type matches (%s); the detected type may not be the right one, use 'ddev config --project-type' to choose", strings.Join(names, ", ")))
+	}
+	if len(report.Docroots) > 1 {
+		var names []string
+		for _, d := range report.Docroots {
+			names = append(names, DocrootDisplayName(d.Docroot))
+		}
+		report.Warnings = append(report.Warnings, fmt.Sprintf("More than one directory could be the docroot (%s), use 'ddev config --docroot' to choose", strings.Join(names, ", ")))
+	}
+	if len(report.Matches) == 0 {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("No specific project type matches, so the project type will be %s", AppTypePHP))
+	}
+
+	return report
+}
+
+// existingFiles returns the files matching globs relative to dir in the
+// project, as paths relative to the project root.
+func (app *DdevApp) existingFiles(dir string, globs ...string) []string {
+	files := []string{}
+	for _, g := range globs {
+		// Plain paths are checked directly, so they're found even if the
+		// project root has glob characters in it.
+		if !strings.ContainsAny(g, "*?[") {
+			if fileutil.FileExists(filepath.Join(app.AppRoot, dir, g)) {
+				files = append(files, filepath.Join(dir, filepath.FromSlash(g)))
+			}
+			continue
+		}
+		matches, _ := filepath.Glob(filepath.Join(app.AppRoot, dir, g))
+		for _, m := range matches {
+			if rel, err := filepath.Rel(app.AppRoot, m); err == nil {
+				files = append(files, rel)
+			}
+		}
+	}
+	return files
+}
+
+// inDir returns true if all the files (relative to the project root) are in
+// dir.
+func inDir(dir string, files []string) bool {
+	for _, f := range files {
+		if !strings.HasPrefix(f, dir+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
+
+// DocrootDisplayName returns a docroot for display, showing the project root as ".".
+func DocrootDisplayName(docroot string) string {
+	if docroot == "" {
+		return "."
+	}
+	return docroot
+}
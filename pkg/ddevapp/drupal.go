@@ -316,15 +316,7 @@ func createDrupal7SettingsFile(app *DdevApp) (string, error) {
 	// we may want to do some kind of customization in the future.
 	drupalConfig := NewDrupalSettings(app)
 
-	if err := manageDrupalSettingsFile(app, drupalConfig, drupal7SettingsTemplate, drupal7SettingsAppendTemplate); err != nil {
-		return "", err
-	}
-
-	if err := writeDrupal7DdevSettingsFile(drupalConfig, app.SiteDdevSettingsFile); err != nil {
-		return "", fmt.Errorf("`failed to write` Drupal settings file %s: %v", app.SiteDdevSettingsFile, err)
-	}
-
-	return app.SiteDdevSettingsFile, nil
+	return createDrupalSettingsFiles(app, drupalConfig, drupal7SettingsTemplate, drupal7SettingsAppendTemplate, writeDrupal7DdevSettingsFile)
 }
 
 // createDrupal8SettingsFile manages creation and modification of settings.php and settings.ddev.php.
@@ -335,15 +327,7 @@ func createDrupal8SettingsFile(app *DdevApp) (string, error) {
 	// we may want to do some kind of customization in the future.
 	drupalConfig := NewDrupalSettings(app)
 
-	if err := manageDrupalSettingsFile(app, drupalConfig, drupal8SettingsTemplate, drupal8SettingsAppendTemplate); err != nil {
-		return "", err
-	}
-
-	if err := writeDrupal8DdevSettingsFile(drupalConfig, app.SiteDdevSettingsFile); err != nil {
-		return "", fmt.Errorf("failed to write Drupal settings file %s: %v", app.SiteDdevSettingsFile, err)
-	}
-
-	return app.SiteDdevSettingsFile, nil
+	return createDrupalSettingsFiles(app, drupalConfig, drupal8SettingsTemplate, drupal8SettingsAppendTemplate, writeDrupal8DdevSettingsFile)
 }
 
 // createDrupal6SettingsFile manages creation and modification of settings.php and settings.ddev.php.
@@ -356,15 +340,7 @@ func createDrupal6SettingsFile(app *DdevApp) (string, error) {
 	// mysqli is required in latest D6LTS and works fine in ddev in old D6
 	drupalConfig.DatabaseDriver = "mysqli"
 
-	if err := manageDrupalSettingsFile(app, drupalConfig, drupal6SettingsTemplate, drupal6SettingsAppendTemplate); err != nil {
-		return "", err
-	}
-
-	if err := writeDrupal6DdevSettingsFile(drupalConfig, app.SiteDdevSettingsFile); err != nil {
-		return "", fmt.Errorf("failed to write Drupal settings file %s: %v", app.SiteDdevSettingsFile, err)
-	}
-
-	return app.SiteDdevSettingsFile, nil
+	return createDrupalSettingsFiles(app, drupalConfig, drupal6SettingsTemplate, drupal6SettingsAppendTemplate, writeDrupal6DdevSettingsFile)
 }
 
 // writeDrupal8DdevSettingsFile dynamically produces valid settings.ddev.php file by combining a configuration
@@ -517,10 +493,7 @@ func WriteDrushrc(app *DdevApp, filePath string) error {
 		}
 	}
 
-	uri := app.GetHTTPSURL()
-	if GetCAROOT() == "" {
-		uri = app.GetHTTPURL()
-	}
+	uri := app.getSettingsURL(GetCAROOT() != "")
 	drushContents := []byte(`<?php
 /** ` + DdevFileSignature + `: Automatically generated drushrc.php file.
  ddev manages this file and may delete or overwrite the file unless this comment is removed.
@@ -563,10 +536,7 @@ func WriteDrushYML(app *DdevApp, filePath string) error {
 		}
 	}
 
-	uri := app.GetHTTPSURL()
-	if GetCAROOT() == "" {
-		uri = app.GetHTTPURL()
-	}
+	uri := app.getSettingsURL(GetCAROOT() != "")
 	drushContents := []byte(`
 #` + DdevFileSignature + `: Automatically generated TYPO3 AdditionalConfiguration.php file.
 # ddev manages this file and may delete or overwrite the file unless this comment is removed.
@@ -639,28 +609,19 @@ func setDrupalSiteSettingsPaths(app *DdevApp) {
 	app.SiteDdevSettingsFile = filepath.Join(settingsFileBasePath, drupalConfig.SitePath, drupalConfig.SiteSettingsDdev)
 }
 
-// isDrupal7App returns true if the app is of type drupal7
-func isDrupal7App(app *DdevApp) bool {
-	if _, err := os.Stat(filepath.Join(app.AppRoot, app.Docroot, "misc/ajax.js")); err == nil {
-		return true
-	}
-	return false
+// detectDrupal7App returns the files showing the app is of type drupal7.
+func detectDrupal7App(app *DdevApp) []string {
+	return app.existingFiles(app.Docroot, "misc/ajax.js")
 }
 
-// isDrupal8App returns true if the app is of type drupal8
-func isDrupal8App(app *DdevApp) bool {
-	if _, err := os.Stat(filepath.Join(app.AppRoot, app.Docroot, "core/scripts/drupal.sh")); err == nil {
-		return true
-	}
-	return false
+// detectDrupal8App returns the files showing the app is of type drupal8.
+func detectDrupal8App(app *DdevApp) []string {
+	return app.existingFiles(app.Docroot, "core/scripts/drupal.sh")
 }
 
-// isDrupal6App returns true if the app is of type Drupal6
-func isDrupal6App(app *DdevApp) bool {
-	if _, err := os.Stat(filepath.Join(app.AppRoot, app.Docroot, "misc/ahah.js")); err == nil {
-		return true
-	}
-	return false
+// detectDrupal6App returns the files showing the app is of type Drupal6.
+func detectDrupal6App(app *DdevApp) []string {
+	return app.existingFiles(app.Docroot, "misc/ahah.js")
 }
 
 // drupal6ConfigOverrideAction overrides php_version for D6, since it is incompatible
@@ -677,6 +638,10 @@ func drupal8PostStartAction(app *DdevApp) error {
 		return err
 	}
 
+	if err := createDrupalMultisiteDatabases(app); err != nil {
+		return err
+	}
+
 	if err := drupalEnsureWritePerms(app); err != nil {
 		return err
 	}
@@ -703,6 +668,10 @@ func drupal8PostStartAction(app *DdevApp) error {
 // drupal7PostStartAction handles default post-start actions for D7 apps, like ensuring
 // useful permissions settings on sites/default.
 func drupal7PostStartAction(app *DdevApp) error {
+	if err := createDrupalMultisiteDatabases(app); err != nil {
+		return err
+	}
+
 	if err := drupalEnsureWritePerms(app); err != nil {
 		return err
 	}
@@ -721,6 +690,10 @@ func drupal7PostStartAction(app *DdevApp) error {
 // drupal6PostStartAction handles default post-start actions for D6 apps, like ensuring
 // useful permissions settings on sites/default.
 func drupal6PostStartAction(app *DdevApp) error {
+	if err := createDrupalMultisiteDatabases(app); err != nil {
+		return err
+	}
+
 	if err := drupalEnsureWritePerms(app); err != nil {
 		return err
 	}
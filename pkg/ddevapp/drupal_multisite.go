@@ -0,0 +1,240 @@
+package ddevapp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/drud/ddev/pkg/fileutil"
+	"github.com/drud/ddev/pkg/output"
+	"github.com/drud/ddev/pkg/util"
+)
+
+// DrupalMultisite is one site of a Drupal multisite project, configured
+// with drupal_multisites in config.yaml.
+type DrupalMultisite struct {
+	// SiteDir is the site's directory in sites/, like "site1".
+	SiteDir string `yaml:"site_dir"`
+	// Database is the name of the site's database; defaults to SiteDir.
+	Database string `yaml:"database,omitempty"`
+	// Hostname is the site's hostname, used like an additional_hostnames
+	// entry; defaults to SiteDir.
+	Hostname string `yaml:"hostname,omitempty"`
+}
+
+// drupalSitesDdevFile is the ddev-managed file with the sites.php entries for the multisites.
+const drupalSitesDdevFile = "sites.ddev.php"
+
+var multisiteDirRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+var multisiteDatabaseRegex = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// GetDatabase returns the name of the site's database.
+func (site DrupalMultisite) GetDatabase() string {
+	if site.Database != "" {
+		return site.Database
+	}
+	return regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(site.SiteDir, "_")
+}
+
+// GetHostname returns the site's hostname, without the project TLD.
+func (site DrupalMultisite) GetHostname() string {
+	if site.Hostname != "" {
+		return site.Hostname
+	}
+	return strings.NewReplacer(".", "-", "_", "-").Replace(site.SiteDir)
+}
+
+// validateDrupalMultisites makes sure the drupal_multisites entries are
+// usable: a Drupal project type, distinct directories, databases and valid
+// hostnames that differ from each other and from the project's hostnames.
+func (app *DdevApp) validateDrupalMultisites() error {
+	if len(app.DrupalMultisites) == 0 {
+		return nil
+	}
+	if app.Type != AppTypeDrupal6 && app.Type != AppTypeDrupal7 && app.Type != AppTypeDrupal8 {
+		return fmt.Errorf("drupal_multisites can only be used with drupal6, drupal7 or drupal8 projects, not %s", app.Type).(invalidDrupalMultisite)
+	}
+
+	// The multisite hostnames may not be any of the project's own hostnames.
+	ownHostnames := map[string]bool{app.GetHostname(): true}
+	for _, name := range app.AdditionalHostnames {
+		ownHostnames[name+"."+app.ProjectTLD] = true
+	}
+	for _, name := range app.AdditionalFQDNs {
+		ownHostnames[name] = true
+	}
+
+	dirs := map[string]bool{"default": true}
+	databases := map[string]bool{"db": true}
+	hostnames := map[string]bool{}
+	for _, site := range app.DrupalMultisites {
+		if !multisiteDirRegex.MatchString(site.SiteDir) || site.SiteDir == "all" {
+			return fmt.Errorf("invalid drupal_multisites site_dir '%s', it must be the name of a directory in sites/", site.SiteDir).(invalidDrupalMultisite)
+		}
+		if dirs[site.SiteDir] {
+			return fmt.Errorf("drupal_multisites site_dir '%s' is used more than once or is the default site", site.SiteDir).(invalidDrupalMultisite)
+		}
+		dirs[site.SiteDir] = true
+
+		db := site.GetDatabase()
+		if !multisiteDatabaseRegex.MatchString(db) {
+			return fmt.Errorf("invalid drupal_multisites database '%s' for %s, use only letters, digits and underscores", db, site.SiteDir).(invalidDrupalMultisite)
+		}
+		if databases[db] {
+			return fmt.Errorf("drupal_multisites database '%s' for %s is used by another site", db, site.SiteDir).(invalidDrupalMultisite)
+		}
+		databases[db] = true
+
+		// The hostname goes into sites.ddev.php as a PHP string, which hostRegex
+		// makes sure needs no escaping.
+		hostname := site.GetHostname() + "." + app.ProjectTLD
+		if !hostRegex.MatchString(hostname) {
+			return fmt.Errorf("invalid drupal_multisites hostname '%s' for %s. See https://en.wikipedia.org/wiki/Hostname#Restrictions_on_valid_hostnames for valid hostname requirements", hostname, site.SiteDir).(invalidDrupalMultisite)
+		}
+		if ownHostnames[hostname] {
+			return fmt.Errorf("drupal_multisites hostname '%s' for %s is already a hostname of the project", hostname, site.SiteDir).(invalidDrupalMultisite)
+		}
+		if hostnames[hostname] {
+			return fmt.Errorf("drupal_multisites hostname '%s' for %s is used by another site, set a distinct hostname for it", hostname, site.SiteDir).(invalidDrupalMultisite)
+		}
+		hostnames[hostname] = true
+	}
+	return nil
+}
+
+// drupalMultisiteSettings returns a copy of app whose settings paths point
+// into the site's directory, and the site's settings based on defaults.
+func (app *DdevApp) drupalMultisiteSettings(site DrupalMultisite, defaults *DrupalSettings) (*DdevApp, *DrupalSettings) {
+	siteConfig := *defaults
+	siteConfig.SitePath = path.Join("sites", site.SiteDir)
+	siteConfig.DatabaseName = site.GetDatabase()
+	siteConfig.HashSalt = util.RandString(64)
+
+	siteApp := *app
+	sitesDir := filepath.Join(app.AppRoot, app.Docroot)
+	siteApp.SiteSettingsPath = filepath.Join(sitesDir, siteConfig.SitePath, siteConfig.SiteSettings)
+	siteApp.SiteDdevSettingsFile = filepath.Join(sitesDir, siteConfig.SitePath, siteConfig.SiteSettingsDdev)
+	return &siteApp, &siteConfig
+}
+
+// createDrupalSettingsFiles writes settings.php and settings.ddev.php for the
+// default site and each of the multisites, plus the sites.php entries mapping
+// the multisite hostnames to their directories.
+func createDrupalSettingsFiles(app *DdevApp, drupalConfig *DrupalSettings, settingsTemplate, appendTemplate string, writeDdevSettings func(*DrupalSettings, string) error) (string, error) {
+	if err := manageDrupalSettingsFile(app, drupalConfig, settingsTemplate, appendTemplate); err != nil {
+		return "", err
+	}
+
+	if err := writeDdevSettings(drupalConfig, app.SiteDdevSettingsFile); err != nil {
+		return "", fmt.Errorf("failed to write Drupal settings file %s: %v", app.SiteDdevSettingsFile, err)
+	}
+
+	for _, site := range app.DrupalMultisites {
+		siteApp, siteConfig := app.drupalMultisiteSettings(site, drupalConfig)
+		if err := manageDrupalSettingsFile(siteApp, siteConfig, settingsTemplate, appendTemplate); err != nil {
+			return "", err
+		}
+		if err := writeDdevSettings(siteConfig, siteApp.SiteDdevSettingsFile); err != nil {
+			return "", fmt.Errorf("failed to write Drupal settings file %s: %v", siteApp.SiteDdevSettingsFile, err)
+		}
+		if err := CreateGitIgnore(filepath.Dir(siteApp.SiteDdevSettingsFile), siteConfig.SiteSettingsDdev); err != nil {
+			util.Warning("Failed to write .gitignore in %s: %v", filepath.Dir(siteApp.SiteDdevSettingsFile), err)
+		}
+	}
+
+	if len(app.DrupalMultisites) > 0 {
+		if err := writeDrupalSitesFiles(app); err != nil {
+			return "", fmt.Errorf("failed to write sites.php: %v", err)
+		}
+	}
+
+	return app.SiteDdevSettingsFile, nil
+}
+
+// writeDrupalSitesFiles writes sites/sites.ddev.php with the $sites entries
+// for the multisites, and makes sure sites/sites.php includes it.
+func writeDrupalSitesFiles(app *DdevApp) error {
+	sitesDir := filepath.Join(app.AppRoot, app.Docroot, "sites")
+	ddevFile := filepath.Join(sitesDir, drupalSitesDdevFile)
+
+	if fileutil.FileExists(ddevFile) {
+		signatureFound, err := fileutil.FgrepStringInFile(ddevFile, DdevFileSignature)
+		if err != nil {
+			return err
+		}
+		if !signatureFound {
+			util.Warning("%s already exists and is managed by the user.", drupalSitesDdevFile)
+			return nil
+		}
+	}
+
+	var entries []string
+	for _, site := range app.DrupalMultisites {
+		entries = append(entries, fmt.Sprintf("$sites['%s.%s'] = '%s';", site.GetHostname(), app.ProjectTLD, site.SiteDir))
+	}
+	sort.Strings(entries)
+	contents := `<?php
+/**
+ * @file
+ * ` + DdevFileSignature + `: Automatically generated Drupal multisite directory aliases.
+ * ddev manages this file and may delete or overwrite the file unless this
+ * comment is removed.
+ */
+
+` + strings.Join(entries, "\n") + "\n"
+	if err := ioutil.WriteFile(ddevFile, []byte(contents), 0644); err != nil {
+		return err
+	}
+
+	include := `
+// Automatically generated include for multisite aliases managed by ddev.
+if (file_exists(__DIR__ . '/` + drupalSitesDdevFile + `')) {
+  include __DIR__ . '/` + drupalSitesDdevFile + `';
+}
+`
+	sitesFile := filepath.Join(sitesDir, "sites.php")
+	if !fileutil.FileExists(sitesFile) {
+		output.UserOut.Printf("No sites.php file exists, creating one")
+		return ioutil.WriteFile(sitesFile, []byte("<?php\n// "+DdevFileSignature+": Automatically generated Drupal sites.php file.\n"+include), 0644)
+	}
+
+	included, err := fileutil.FgrepStringInFile(sitesFile, drupalSitesDdevFile)
+	if err != nil || included {
+		return err
+	}
+	output.UserOut.Printf("Existing sites.php file does not include %s, modifying to include ddev multisite aliases", drupalSitesDdevFile)
+	file, err := os.OpenFile(sitesFile, os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer util.CheckClose(file)
+	_, err = file.WriteString(include)
+	return err
+}
+
+// createDrupalMultisiteDatabases creates the databases of the multisites,
+// which the db container doesn't know about, and grants the db user access.
+func createDrupalMultisiteDatabases(app *DdevApp) error {
+	if len(app.DrupalMultisites) == 0 {
+		return nil
+	}
+
+	var statements []string
+	for _, site := range app.DrupalMultisites {
+		db := site.GetDatabase()
+		statements = append(statements, fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`; GRANT ALL ON `%s`.* TO 'db'@'%%';", db, db))
+	}
+	stdout, stderr, err := app.Exec(&ExecOpts{
+		Service: "db",
+		Cmd:     "mysql -e " + util.ShellQuote(strings.Join(statements, " ")),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create multisite databases: %v, output=%s%s", err, stdout, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package ddevapp
+
+import (
+	"bufio"
+	"fmt"
+	"html/template"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/drud/ddev/pkg/fileutil"
+)
+
+// EnvFile is the file in .ddev with environment variables for the web and
+// db containers, in addition to web_environment and db_environment.
+// It's not called .env, which docker-compose would read by itself from the
+// .ddev directory to interpolate the compose files.
+const EnvFile = "project.env"
+
+// environmentEntryRegex matches a web_environment or db_environment entry, KEY=value.
+var environmentEntryRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*=`)
+
+// validateEnvironment makes sure the web_environment and db_environment
+// entries look like KEY=value.
+func (app *DdevApp) validateEnvironment() error {
+	for key, entries := range map[string][]string{"web_environment": app.WebEnvironment, "db_environment": app.DBEnvironment} {
+		for _, entry := range entries {
+			if !environmentEntryRegex.MatchString(entry) {
+				return fmt.Errorf("invalid %s entry '%s', use KEY=value", key, entry).(invalidEnvironment)
+			}
+		}
+	}
+	return nil
+}
+
+// ReadEnvFile reads KEY=value lines from an env file, skipping empty lines
+// and comments. An optional "export " prefix and quotes around the value
+// are removed, so the file can be sourced by a shell too.
+func ReadEnvFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	// nolint: errcheck
+	defer f.Close()
+
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		if !environmentEntryRegex.MatchString(line) {
+			return nil, fmt.Errorf("invalid line %d in %s, use KEY=value", lineNum, path)
+		}
+		parts := strings.SplitN(line, "=", 2)
+		value := parts[1]
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		entries = append(entries, parts[0]+"="+value)
+	}
+	return entries, scanner.Err()
+}
+
+// GetWebEnvironment returns the environment variables for the web container,
+// the ones in .ddev/project.env overridden by web_environment, as KEY=value.
+func (app *DdevApp) GetWebEnvironment() ([]string, error) {
+	return app.serviceEnvironment(app.WebEnvironment)
+}
+
+// GetDBEnvironment returns the environment variables for the db container,
+// the ones in .ddev/project.env overridden by db_environment, as KEY=value.
+func (app *DdevApp) GetDBEnvironment() ([]string, error) {
+	return app.serviceEnvironment(app.DBEnvironment)
+}
+
+// serviceEnvironment merges the .ddev/project.env entries and a service's
+// configured entries, keeping the order they're first defined in.
+func (app *DdevApp) serviceEnvironment(configured []string) ([]string, error) {
+	var entries []string
+	if envFile := app.GetConfigPath(EnvFile); fileutil.FileExists(envFile) {
+		fileEntries, err := ReadEnvFile(envFile)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	entries = append(entries, configured...)
+
+	var merged []string
+	index := map[string]int{}
+	for _, entry := range entries {
+		key := strings.SplitN(entry, "=", 2)[0]
+		if i, ok := index[key]; ok {
+			merged[i] = entry
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, entry)
+	}
+	return merged, nil
+}
+
+// composeEnvironment renders environment entries as docker-compose list
+// items: quoted YAML strings, with $ escaped so docker-compose doesn't
+// interpolate it. They're marked safe so the compose template keeps them as is.
+func composeEnvironment(entries []string) []template.HTML {
+	var items []template.HTML
+	for _, entry := range entries {
+		items = append(items, template.HTML(strconv.Quote(strings.Replace(entry, "$", "$$", -1))))
+	}
+	return items
+}
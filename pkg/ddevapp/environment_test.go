@@ -0,0 +1,74 @@
+package ddevapp_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/testcommon"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+// TestEnvironment makes sure web_environment, db_environment and .ddev/project.env
+// are validated, merged and rendered into the web and db services.
+func TestEnvironment(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	app.Name = "environment"
+
+	app.WebEnvironment = []string{"not a variable"}
+	err = app.ValidateConfig()
+	assert.Error(err)
+	assert.Contains(err.Error(), "invalid web_environment entry")
+
+	err = os.MkdirAll(filepath.Join(testDir, ".ddev"), 0755)
+	require.NoError(t, err)
+	err = ioutil.WriteFile(app.GetConfigPath(ddevapp.EnvFile), []byte(`# Local secrets
+API_KEY=from-env-file
+export FEATURE_FLAG="on"
+
+QUOTED='a b'
+`), 0644)
+	require.NoError(t, err)
+
+	app.WebEnvironment = []string{"API_KEY=from-config", "PRICE=$5 \"each\""}
+	app.DBEnvironment = []string{"MYSQL_FLAG=1"}
+	assert.NoError(app.ValidateConfig())
+
+	webEnvironment, err := app.GetWebEnvironment()
+	require.NoError(t, err)
+	assert.Equal([]string{"API_KEY=from-config", "FEATURE_FLAG=on", "QUOTED=a b", `PRICE=$5 "each"`}, webEnvironment)
+	dbEnvironment, err := app.GetDBEnvironment()
+	require.NoError(t, err)
+	assert.Equal([]string{"API_KEY=from-env-file", "FEATURE_FLAG=on", "QUOTED=a b", "MYSQL_FLAG=1"}, dbEnvironment)
+
+	content, err := app.RenderComposeYAML()
+	require.NoError(t, err)
+	var compose struct {
+		Services map[string]struct {
+			Environment []string `yaml:"environment"`
+		} `yaml:"services"`
+	}
+	err = yaml.Unmarshal([]byte(content), &compose)
+	require.NoError(t, err)
+	// docker-compose turns $$ back into $.
+	assert.Contains(compose.Services["web"].Environment, `PRICE=$$5 "each"`)
+	assert.Contains(compose.Services["web"].Environment, "QUOTED=a b")
+	assert.Contains(compose.Services["db"].Environment, "MYSQL_FLAG=1")
+	assert.NotContains(compose.Services["db"].Environment, `PRICE=$$5 "each"`)
+
+	err = ioutil.WriteFile(app.GetConfigPath(ddevapp.EnvFile), []byte("not a variable\n"), 0644)
+	require.NoError(t, err)
+	_, err = app.GetWebEnvironment()
+	assert.Error(err)
+}
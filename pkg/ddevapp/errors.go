@@ -10,3 +10,9 @@ type invalidProvider error
 type InvalidOmitContainers error
 type webContainerExists error
 type invalidMariaDBVersion error
+type invalidProfiler error
+type invalidDrupalMultisite error
+type invalidNodeJSVersion error
+type invalidComposerVersion error
+type invalidPHPExtension error
+type invalidEnvironment error
@@ -0,0 +1,186 @@
+package ddevapp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/drud/ddev/pkg/dockerutil"
+	"github.com/fsouza/go-dockerclient"
+)
+
+// Project events, the states a project goes through as its containers
+// start, become healthy, are stopped (paused) and are removed (stopped).
+const (
+	ProjectEventStarting  = "starting"
+	ProjectEventHealthy   = "healthy"
+	ProjectEventUnhealthy = "unhealthy"
+	ProjectEventPaused    = SitePaused
+	ProjectEventStopped   = SiteStopped
+)
+
+// siteNameLabel is the label of the containers of a project with its name.
+const siteNameLabel = "com.ddev.site-name"
+
+// ProjectEvent is a change of the state of a project.
+type ProjectEvent struct {
+	Project string    `json:"project"`
+	Event   string    `json:"event"`
+	Time    time.Time `json:"time"`
+	// Service is the service whose container caused the change, like "web".
+	Service string `json:"service"`
+}
+
+// ProjectEventTracker turns the states of the containers of projects into
+// project events.
+type ProjectEventTracker struct {
+	// services are the states of the services of each project.
+	services map[string]map[string]string
+	// states are the states of the projects.
+	states map[string]string
+}
+
+// NewProjectEventTracker returns a ProjectEventTracker that knows of no projects.
+func NewProjectEventTracker() *ProjectEventTracker {
+	return &ProjectEventTracker{
+		services: map[string]map[string]string{},
+		states:   map[string]string{},
+	}
+}
+
+// Update sets the state of a service of project, one of the ProjectEvent
+// states but stopped, or "" if its container was removed. It returns the
+// event if the state of the project changes, nil otherwise.
+func (t *ProjectEventTracker) Update(project string, service string, state string, at time.Time) *ProjectEvent {
+	services, ok := t.services[project]
+	if !ok {
+		services = map[string]string{}
+		t.services[project] = services
+	}
+	if state == "" {
+		delete(services, service)
+	} else {
+		services[service] = state
+	}
+
+	projectState := ProjectEventStopped
+	if len(services) > 0 {
+		projectState = ProjectEventHealthy
+		// The first state of these that any service is in is the state of
+		// the project, so it's only healthy if all its services are.
+		for _, s := range []string{ProjectEventUnhealthy, ProjectEventPaused, ProjectEventStarting} {
+			if serviceStatesContain(services, s) {
+				projectState = s
+				break
+			}
+		}
+	} else {
+		delete(t.services, project)
+	}
+
+	previous, known := t.states[project]
+	t.states[project] = projectState
+	if projectState == previous || (!known && projectState == ProjectEventStopped) {
+		return nil
+	}
+	return &ProjectEvent{Project: project, Event: projectState, Time: at, Service: service}
+}
+
+// serviceStatesContain tells whether any of the services is in state.
+func serviceStatesContain(services map[string]string, state string) bool {
+	for _, s := range services {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// containerServiceState is the state of the service of a container: paused
+// if it's not running, and otherwise its health, or healthy if it has no
+// healthcheck.
+func containerServiceState(container *docker.Container) string {
+	switch {
+	case !container.State.Running || container.State.Paused:
+		return ProjectEventPaused
+	case container.State.Health.Status == "" || container.State.Health.Status == "healthy":
+		return ProjectEventHealthy
+	case container.State.Health.Status == "unhealthy":
+		return ProjectEventUnhealthy
+	default:
+		return ProjectEventStarting
+	}
+}
+
+// WatchProjectEvents sends the events of all projects to events, by
+// following the Docker events of the containers labeled with a project name,
+// until stop is closed. The current states of the projects aren't sent.
+func WatchProjectEvents(stop <-chan struct{}, events chan<- ProjectEvent) error {
+	client := dockerutil.GetDockerClient()
+	dockerEvents := make(chan *docker.APIEvents, 100)
+	if err := client.AddEventListener(dockerEvents); err != nil {
+		return fmt.Errorf("failed to listen to docker events: %v", err)
+	}
+	// nolint: errcheck
+	defer client.RemoveEventListener(dockerEvents)
+
+	tracker := NewProjectEventTracker()
+	containers, err := dockerutil.GetDockerContainers(true)
+	if err != nil {
+		return err
+	}
+	for _, c := range containers {
+		project, ok := c.Labels[siteNameLabel]
+		if !ok {
+			continue
+		}
+		container, err := client.InspectContainer(c.ID)
+		if err != nil {
+			continue
+		}
+		tracker.Update(project, c.Labels["com.docker.compose.service"], containerServiceState(container), time.Now())
+	}
+
+	for {
+		select {
+		case <-stop:
+			return nil
+		case e, ok := <-dockerEvents:
+			if !ok {
+				return fmt.Errorf("lost the connection to docker events")
+			}
+			project, ok := e.Actor.Attributes[siteNameLabel]
+			if e.Type != "container" || !ok {
+				continue
+			}
+
+			var state string
+			switch e.Action {
+			case "start", "unpause":
+				container, err := client.InspectContainer(e.Actor.ID)
+				if err != nil {
+					continue
+				}
+				state = containerServiceState(container)
+			case "health_status: healthy":
+				state = ProjectEventHealthy
+			case "health_status: unhealthy":
+				state = ProjectEventUnhealthy
+			case "die", "pause":
+				state = ProjectEventPaused
+			case "destroy":
+				state = ""
+			default:
+				continue
+			}
+
+			at := time.Unix(0, e.TimeNano)
+			if event := tracker.Update(project, e.Actor.Attributes["com.docker.compose.service"], state, at); event != nil {
+				select {
+				case events <- *event:
+				case <-stop:
+					return nil
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ddevapp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	asrt "github.com/stretchr/testify/assert"
+)
+
+// TestProjectEventTracker makes sure the states of the containers of a
+// project are turned into project events as they change.
+func TestProjectEventTracker(t *testing.T) {
+	assert := asrt.New(t)
+	tracker := ddevapp.NewProjectEventTracker()
+	now := time.Now()
+
+	events := func(updates ...[3]string) []string {
+		var events []string
+		for _, u := range updates {
+			if event := tracker.Update(u[0], u[1], u[2], now); event != nil {
+				assert.Equal(u[0], event.Project)
+				assert.Equal(u[1], event.Service)
+				events = append(events, event.Event)
+			}
+		}
+		return events
+	}
+
+	// Removing the containers of a project that's not known isn't an event.
+	assert.Empty(events([3]string{"unknown", "web", ""}))
+
+	// The project is only healthy when all its services are.
+	assert.Equal([]string{ddevapp.ProjectEventStarting, ddevapp.ProjectEventHealthy}, events(
+		[3]string{"proj", "db", ddevapp.ProjectEventStarting},
+		[3]string{"proj", "web", ddevapp.ProjectEventStarting},
+		[3]string{"proj", "db", ddevapp.ProjectEventHealthy},
+		[3]string{"proj", "web", ddevapp.ProjectEventHealthy},
+	))
+
+	// Other projects are tracked separately.
+	assert.Equal([]string{ddevapp.ProjectEventHealthy}, events([3]string{"other", "web", ddevapp.ProjectEventHealthy}))
+
+	assert.Equal([]string{ddevapp.ProjectEventUnhealthy, ddevapp.ProjectEventHealthy}, events(
+		[3]string{"proj", "web", ddevapp.ProjectEventUnhealthy},
+		[3]string{"proj", "web", ddevapp.ProjectEventHealthy},
+	))
+
+	// Stopping the containers pauses the project, removing them stops it.
+	assert.Equal([]string{ddevapp.ProjectEventPaused, ddevapp.ProjectEventStopped}, events(
+		[3]string{"proj", "web", ddevapp.ProjectEventPaused},
+		[3]string{"proj", "db", ddevapp.ProjectEventPaused},
+		[3]string{"proj", "web", ""},
+		[3]string{"proj", "db", ""},
+	))
+
+	assert.Equal([]string{ddevapp.ProjectEventStarting}, events([3]string{"proj", "web", ddevapp.ProjectEventStarting}))
+}
@@ -0,0 +1,226 @@
+package ddevapp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/drud/ddev/pkg/appports"
+	"github.com/drud/ddev/pkg/archive"
+	"github.com/drud/ddev/pkg/fileutil"
+	"github.com/drud/ddev/pkg/output"
+)
+
+// laravelHooks are the hook suggestions added to config.yaml for Laravel projects.
+const laravelHooks = `
+# Un-comment to link the public storage directory and run migrations after ddev start.
+#  post-start:
+#    - exec: php artisan storage:link
+#    - exec: php artisan migrate
+# Un-comment to clear the caches after importing a database.
+#  post-import-db:
+#    - exec: php artisan cache:clear`
+
+// getLaravelHooks for appending as byte array
+func getLaravelHooks() []byte {
+	return []byte(laravelHooks)
+}
+
+// getLaravelUploadDir will return a custom upload dir if defined, returning a default path if not.
+// Unlike other project types, the Laravel upload dir is relative to the project root,
+// since storage/ lives outside the public docroot.
+func getLaravelUploadDir(app *DdevApp) string {
+	if app.UploadDir == "" {
+		return "storage/app/public"
+	}
+
+	return app.UploadDir
+}
+
+// setLaravelSiteSettingsPaths sets the path to .env, which holds all of the
+// settings ddev manages for Laravel.
+func setLaravelSiteSettingsPaths(app *DdevApp) {
+	app.SiteSettingsPath = filepath.Join(app.AppRoot, ".env")
+	app.SiteDdevSettingsFile = app.SiteSettingsPath
+}
+
+// laravelSettingsPermissions leaves the permissions alone: .env is a
+// user-managed file in the project root that ddev only updates.
+func laravelSettingsPermissions(app *DdevApp) error {
+	return nil
+}
+
+// laravelSettingsGitIgnore doesn't write a .gitignore, since Laravel projects
+// already ignore their .env.
+func laravelSettingsGitIgnore(app *DdevApp) error {
+	return nil
+}
+
+// detectLaravelApp returns the files showing the app is of type "laravel".
+func detectLaravelApp(app *DdevApp) []string {
+	return app.existingFiles("", "artisan")
+}
+
+// laravelConfigOverrideAction sets the Laravel default docroot of "public"
+// if no docroot has been chosen.
+func laravelConfigOverrideAction(app *DdevApp) error {
+	if app.Docroot == "" {
+		app.Docroot = "public"
+	}
+	return nil
+}
+
+// laravelEnvSettings returns the .env keys ddev manages, in the order they
+// are added to a .env that doesn't have them yet.
+func laravelEnvSettings(app *DdevApp) [][2]string {
+	return [][2]string{
+		{"APP_URL", app.getSettingsURL(true)},
+		{"DB_CONNECTION", "mysql"},
+		{"DB_HOST", "db"},
+		{"DB_PORT", appports.GetPort("db")},
+		{"DB_DATABASE", "db"},
+		{"DB_USERNAME", "db"},
+		{"DB_PASSWORD", "db"},
+		// MailHog listens for SMTP inside the web container.
+		{"MAIL_DRIVER", "smtp"},
+		{"MAIL_HOST", "127.0.0.1"},
+		{"MAIL_PORT", "1025"},
+		{"MAIL_USERNAME", "null"},
+		{"MAIL_PASSWORD", "null"},
+		{"MAIL_ENCRYPTION", "null"},
+	}
+}
+
+// createLaravelSettingsFile writes the ddev database and mail settings into
+// .env, creating it from .env.example if it doesn't exist yet. Existing values
+// of the managed keys are replaced in place; all other lines are kept.
+func createLaravelSettingsFile(app *DdevApp) (string, error) {
+	envFile := app.SiteSettingsPath
+
+	if !fileutil.FileExists(envFile) {
+		example := filepath.Join(app.AppRoot, ".env.example")
+		if fileutil.FileExists(example) {
+			output.UserOut.Printf("No .env file exists, creating one from .env.example")
+			if err := fileutil.CopyFile(example, envFile); err != nil {
+				return "", err
+			}
+		} else {
+			output.UserOut.Printf("No .env file exists, creating one")
+		}
+	}
+
+	settings := laravelEnvSettings(app)
+	// Laravel 7 renamed MAIL_DRIVER to MAIL_MAILER; keep whichever the project uses.
+	var contents []byte
+	if fileutil.FileExists(envFile) {
+		var err error
+		contents, err = ioutil.ReadFile(envFile)
+		if err != nil {
+			return "", err
+		}
+	}
+	if regexp.MustCompile(`(?m)^\s*MAIL_MAILER=`).Match(contents) {
+		for i := range settings {
+			if settings[i][0] == "MAIL_DRIVER" {
+				settings[i][0] = "MAIL_MAILER"
+			}
+		}
+	}
+
+	if err := updateEnvFile(envFile, settings); err != nil {
+		return "", fmt.Errorf("failed to update %s: %v", envFile, err)
+	}
+	return envFile, nil
+}
+
+// updateEnvFile sets each key of settings to its value in the dotenv file at
+// envFile, replacing existing assignments and appending missing ones. An
+// existing file keeps its mode, since it may hold secrets.
+func updateEnvFile(envFile string, settings [][2]string) error {
+	contents := ""
+	mode := os.FileMode(0644)
+	if fi, err := os.Stat(envFile); err == nil {
+		mode = fi.Mode().Perm()
+		b, err := ioutil.ReadFile(envFile)
+		if err != nil {
+			return err
+		}
+		contents = string(b)
+	}
+
+	var missing []string
+	for _, s := range settings {
+		line := s[0] + "=" + s[1]
+		re := regexp.MustCompile(`(?m)^[ \t]*(export[ \t]+)?` + regexp.QuoteMeta(s[0]) + `[ \t]*=.*$`)
+		if re.MatchString(contents) {
+			contents = re.ReplaceAllLiteralString(contents, line)
+		} else {
+			missing = append(missing, line)
+		}
+	}
+
+	if len(missing) > 0 {
+		if contents != "" && !strings.HasSuffix(contents, "\n") {
+			contents = contents + "\n"
+		}
+		contents = contents + "\n" + DdevFileSignature + ": settings added by ddev\n" + strings.Join(missing, "\n") + "\n"
+	}
+
+	return ioutil.WriteFile(envFile, []byte(contents), mode)
+}
+
+// laravelImportFilesAction defines the Laravel workflow for importing project files
+// into the public storage directory.
+func laravelImportFilesAction(app *DdevApp, importPath, extPath string) error {
+	destPath := filepath.Join(app.GetAppRoot(), app.GetUploadDir())
+
+	// parent of destination dir should exist
+	if !fileutil.FileExists(filepath.Dir(destPath)) {
+		return fmt.Errorf("unable to import to %s: parent directory does not exist", destPath)
+	}
+
+	// parent of destination dir should be writable.
+	if err := os.Chmod(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+
+	// If the destination path exists, remove it as was warned
+	if fileutil.FileExists(destPath) {
+		if err := os.RemoveAll(destPath); err != nil {
+			return fmt.Errorf("failed to cleanup %s before import: %v", destPath, err)
+		}
+	}
+
+	if isTar(importPath) {
+		if err := archive.Untar(importPath, destPath, extPath); err != nil {
+			return fmt.Errorf("failed to extract provided archive: %v", err)
+		}
+
+		return nil
+	}
+
+	if isZip(importPath) {
+		if err := archive.Unzip(importPath, destPath, extPath); err != nil {
+			return fmt.Errorf("failed to extract provided archive: %v", err)
+		}
+
+		return nil
+	}
+
+	if err := fileutil.CopyDir(importPath, destPath); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// laravelPostStartAction makes sure .env has the ddev settings after start.
+func laravelPostStartAction(app *DdevApp) error {
+	if _, err := app.CreateSettingsFile(); err != nil {
+		return fmt.Errorf("failed to write settings file %s: %v", app.SiteDdevSettingsFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package ddevapp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/drud/ddev/pkg/appports"
+	"github.com/drud/ddev/pkg/archive"
+	"github.com/drud/ddev/pkg/fileutil"
+	"github.com/drud/ddev/pkg/output"
+	"github.com/drud/ddev/pkg/util"
+)
+
+// Magento2Settings holds the values ddev writes into app/etc/env.php.
+type Magento2Settings struct {
+	DatabaseName     string
+	DatabaseUsername string
+	DatabasePassword string
+	DatabaseHost     string
+	CryptKey         string
+	InstallDate      string
+	BaseURL          string
+	SecureBaseURL    string
+	Signature        string
+}
+
+// NewMagento2Settings produces a Magento2Settings object with default values.
+// The base URLs come from the project's primary URL.
+func NewMagento2Settings(app *DdevApp) *Magento2Settings {
+	baseURL := app.getSettingsURL(false) + "/"
+	secureBaseURL := app.getSettingsURL(GetCAROOT() != "") + "/"
+	if urls := app.GetAllURLs(); len(urls) > 0 {
+		secureBaseURL = urls[0] + "/"
+	}
+
+	return &Magento2Settings{
+		DatabaseName:     "db",
+		DatabaseUsername: "db",
+		DatabasePassword: "db",
+		DatabaseHost:     "db:" + appports.GetPort("db"),
+		CryptKey:         strings.ToLower(util.RandString(32)),
+		InstallDate:      time.Now().UTC().Format(time.RFC1123),
+		BaseURL:          baseURL,
+		SecureBaseURL:    secureBaseURL,
+		Signature:        DdevFileSignature,
+	}
+}
+
+// magento2EnvTemplate defines the template that will become app/etc/env.php.
+const magento2EnvTemplate = `<?php
+{{ $config := . }}
+/**
+ {{ $config.Signature }}: Automatically generated Magento 2 env.php file.
+ ddev manages this file and may delete or overwrite the file unless this comment is removed.
+ */
+
+return [
+    'backend' => [
+        'frontName' => 'admin'
+    ],
+    'crypt' => [
+        'key' => '{{ $config.CryptKey }}'
+    ],
+    'db' => [
+        'table_prefix' => '',
+        'connection' => [
+            'default' => [
+                'host' => '{{ $config.DatabaseHost }}',
+                'dbname' => '{{ $config.DatabaseName }}',
+                'username' => '{{ $config.DatabaseUsername }}',
+                'password' => '{{ $config.DatabasePassword }}',
+                'model' => 'mysql4',
+                'engine' => 'innodb',
+                'initStatements' => 'SET NAMES utf8;',
+                'active' => '1'
+            ]
+        ]
+    ],
+    'resource' => [
+        'default_setup' => [
+            'connection' => 'default'
+        ]
+    ],
+    'x-frame-options' => 'SAMEORIGIN',
+    'MAGE_MODE' => 'developer',
+    'session' => [
+        'save' => 'files'
+    ],
+    'cache_types' => [
+        'config' => 1,
+        'layout' => 1,
+        'block_html' => 1,
+        'collections' => 1,
+        'reflection' => 1,
+        'db_ddl' => 1,
+        'eav' => 1,
+        'customer_notification' => 1,
+        'config_integration' => 1,
+        'config_integration_api' => 1,
+        'full_page' => 1,
+        'translate' => 1,
+        'config_webservice' => 1
+    ],
+    'install' => [
+        'date' => '{{ $config.InstallDate }}'
+    ],
+    'system' => [
+        'default' => [
+            'web' => [
+                'unsecure' => [
+                    'base_url' => '{{ $config.BaseURL }}'
+                ],
+                'secure' => [
+                    'base_url' => '{{ $config.SecureBaseURL }}'
+                ]
+            ]
+        ]
+    ]
+];
+`
+
+// magento2Hooks are the hook suggestions added to config.yaml for Magento 2 projects.
+const magento2Hooks = `
+# Un-comment to run the Magento setup upgrade and flush caches after ddev start.
+#  post-start:
+#    - exec: bin/magento setup:upgrade
+#    - exec: bin/magento cache:flush
+# Un-comment to flush caches after importing a database.
+#  post-import-db:
+#    - exec: bin/magento cache:flush`
+
+// getMagento2Hooks for appending as byte array
+func getMagento2Hooks() []byte {
+	return []byte(magento2Hooks)
+}
+
+// getMagento2UploadDir will return a custom upload dir if defined, returning a default path if not.
+// Like Laravel, the Magento 2 upload dir is relative to the project root.
+func getMagento2UploadDir(app *DdevApp) string {
+	if app.UploadDir == "" {
+		return "pub/media"
+	}
+
+	return app.UploadDir
+}
+
+// setMagento2SiteSettingsPaths sets the path to app/etc/env.php, which
+// holds all of the settings ddev manages for Magento 2.
+func setMagento2SiteSettingsPaths(app *DdevApp) {
+	app.SiteSettingsPath = filepath.Join(app.AppRoot, "app", "etc", "env.php")
+	app.SiteDdevSettingsFile = app.SiteSettingsPath
+}
+
+// detectMagento2App returns the files showing the app is of type "magento2".
+func detectMagento2App(app *DdevApp) []string {
+	return app.existingFiles("", "bin/magento")
+}
+
+// magento2ConfigOverrideAction sets the Magento 2 default docroot of "pub"
+// if no docroot has been chosen, and a PHP version Magento 2 supports.
+func magento2ConfigOverrideAction(app *DdevApp) error {
+	if app.Docroot == "" {
+		app.Docroot = "pub"
+	}
+	app.PHPVersion = PHP72
+	return nil
+}
+
+// createMagento2SettingsFile writes app/etc/env.php with the ddev database
+// and base URLs, unless an env.php not managed by ddev already exists.
+func createMagento2SettingsFile(app *DdevApp) (string, error) {
+	if fileutil.FileExists(app.SiteDdevSettingsFile) {
+		// Check if the file is managed by ddev.
+		signatureFound, err := fileutil.FgrepStringInFile(app.SiteDdevSettingsFile, DdevFileSignature)
+		if err != nil {
+			return "", err
+		}
+
+		// If the signature wasn't found, warn the user and return.
+		if !signatureFound {
+			util.Warning("%s already exists and is managed by the user.", filepath.Base(app.SiteDdevSettingsFile))
+			return app.SiteDdevSettingsFile, nil
+		}
+	}
+
+	settings := NewMagento2Settings(app)
+	// Keep the crypt key and install date of an existing env.php, or data
+	// encrypted with the old key becomes unreadable.
+	if contents, err := ioutil.ReadFile(app.SiteDdevSettingsFile); err == nil {
+		if m := regexp.MustCompile(`'crypt' => \[\s*'key' => '([^']+)'`).FindSubmatch(contents); m != nil {
+			settings.CryptKey = string(m[1])
+		}
+		if m := regexp.MustCompile(`'install' => \[\s*'date' => '([^']+)'`).FindSubmatch(contents); m != nil {
+			settings.InstallDate = string(m[1])
+		}
+	}
+
+	output.UserOut.Printf("Generating %s file for database connection.", filepath.Base(app.SiteDdevSettingsFile))
+	if err := writeMagento2SettingsFile(settings, app.SiteDdevSettingsFile); err != nil {
+		return "", fmt.Errorf("failed to write Magento 2 env.php file: %v", err)
+	}
+
+	return app.SiteDdevSettingsFile, nil
+}
+
+// writeMagento2SettingsFile dynamically produces a valid env.php file by
+// combining a configuration object with a data-driven template.
+func writeMagento2SettingsFile(settings *Magento2Settings, filePath string) error {
+	tmpl, err := template.New("settings").Funcs(getTemplateFuncMap()).Parse(magento2EnvTemplate)
+	if err != nil {
+		return err
+	}
+
+	// Ensure target directory exists and is writable
+	dir := filepath.Dir(filePath)
+	if err = os.Chmod(dir, 0755); os.IsNotExist(err) {
+		if err = os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer util.CheckClose(file)
+
+	return tmpl.Execute(file, settings)
+}
+
+// magento2PostImportDBAction points the imported database's base URLs at the
+// project's URLs, since the ones in core_config_data are those of the site
+// the database came from.
+func magento2PostImportDBAction(app *DdevApp) error {
+	settings := NewMagento2Settings(app)
+	query := fmt.Sprintf(`UPDATE core_config_data SET value = '%s' WHERE path = 'web/unsecure/base_url'; UPDATE core_config_data SET value = '%s' WHERE path = 'web/secure/base_url';`, settings.BaseURL, settings.SecureBaseURL)
+
+	stdout, stderr, err := app.Exec(&ExecOpts{
+		Service: "db",
+		Cmd:     "mysql db -e " + util.ShellQuote(query),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update base URLs in core_config_data: %v, output=%s%s", err, stdout, stderr)
+	}
+	output.UserOut.Printf("Updated Magento base URLs to %s", settings.SecureBaseURL)
+	return nil
+}
+
+// magento2ImportFilesAction defines the Magento 2 workflow for importing project files
+// into pub/media.
+func magento2ImportFilesAction(app *DdevApp, importPath, extPath string) error {
+	destPath := filepath.Join(app.GetAppRoot(), app.GetUploadDir())
+
+	// parent of destination dir should exist
+	if !fileutil.FileExists(filepath.Dir(destPath)) {
+		return fmt.Errorf("unable to import to %s: parent directory does not exist", destPath)
+	}
+
+	// parent of destination dir should be writable.
+	if err := os.Chmod(filepath.Dir(destPath), 0755); err != nil {
+		return err
+	}
+
+	// If the destination path exists, remove it as was warned
+	if fileutil.FileExists(destPath) {
+		if err := os.RemoveAll(destPath); err != nil {
+			return fmt.Errorf("failed to cleanup %s before import: %v", destPath, err)
+		}
+	}
+
+	if isTar(importPath) {
+		if err := archive.Untar(importPath, destPath, extPath); err != nil {
+			return fmt.Errorf("failed to extract provided archive: %v", err)
+		}
+
+		return nil
+	}
+
+	if isZip(importPath) {
+		if err := archive.Unzip(importPath, destPath, extPath); err != nil {
+			return fmt.Errorf("failed to extract provided archive: %v", err)
+		}
+
+		return nil
+	}
+
+	if err := fileutil.CopyDir(importPath, destPath); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// magento2PostStartAction writes env.php after start, when the project's URLs are known.
+func magento2PostStartAction(app *DdevApp) error {
+	if _, err := app.CreateSettingsFile(); err != nil {
+		return fmt.Errorf("failed to write settings file %s: %v", app.SiteDdevSettingsFile, err)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package ddevapp
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// nodejsDockerfileRun returns the Dockerfile instruction that installs
+// Node.js major version (like "10") from the NodeSource repository, replacing
+// any Node.js the web image comes with, and yarn.
+func nodejsDockerfileRun(version string) string {
+	return fmt.Sprintf(`RUN curl -sSL https://deb.nodesource.com/setup_%s.x | bash - && \
+  DEBIAN_FRONTEND=noninteractive apt-get install -y nodejs && \
+  npm install --global yarn && \
+  node --version | grep -q '^v%s\.'`, version, version)
+}
+
+// GetContainerWorkingDir returns the directory in the web container matching
+// the current directory on the host, or the project root if the current
+// directory is outside the project.
+func (app *DdevApp) GetContainerWorkingDir() string {
+	containerDir := "/var/www/html"
+	cwd, err := os.Getwd()
+	if err != nil {
+		return containerDir
+	}
+	rel, err := filepath.Rel(app.AppRoot, cwd)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return containerDir
+	}
+	return path.Join(containerDir, filepath.ToSlash(rel))
+}
@@ -0,0 +1,46 @@
+package ddevapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/testcommon"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNodeJSVersion makes sure nodejs_version is validated and installs the
+// chosen Node.js in the web image.
+func TestNodeJSVersion(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	app.Name = "nodejsversion"
+
+	app.NodeJSVersion = "7"
+	err = app.ValidateConfig()
+	assert.Error(err)
+	assert.Contains(err.Error(), "invalid nodejs_version")
+
+	app.NodeJSVersion = "10"
+	assert.NoError(app.ValidateConfig())
+	content, err := app.RenderComposeYAML()
+	require.NoError(t, err)
+	assert.Contains(content, ".webimageExtra")
+	dockerfile, err := ioutil.ReadFile(filepath.Join(app.GetConfigPath(".webimageExtra"), "Dockerfile"))
+	require.NoError(t, err)
+	assert.Contains(string(dockerfile), "https://deb.nodesource.com/setup_10.x")
+	assert.Contains(string(dockerfile), "npm install --global yarn")
+
+	// npm and yarn run in the container directory matching the current one.
+	assert.Equal("/var/www/html", app.GetContainerWorkingDir())
+	defer testcommon.Chdir(filepath.Join(testDir, ".ddev"))()
+	assert.Equal("/var/www/html/.ddev", app.GetContainerWorkingDir())
+}
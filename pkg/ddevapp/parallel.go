@@ -0,0 +1,72 @@
+package ddevapp
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ProjectResult is the outcome of an operation on one project.
+type ProjectResult struct {
+	App      *DdevApp
+	Err      error
+	Duration time.Duration
+}
+
+// RunOnProjects runs op on each of apps, with at most workers of them at the
+// same time, and returns the result for each app in the order of apps.
+// Changes to the router, the hosts file and the global config are serialized,
+// so op can be an operation like Start, Stop or SnapshotDatabase.
+func RunOnProjects(apps []*DdevApp, workers int, op func(app *DdevApp) error) []ProjectResult {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(apps) {
+		workers = len(apps)
+	}
+
+	results := make([]ProjectResult, len(apps))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				start := time.Now()
+				err := runProjectOp(apps[i], op)
+				results[i] = ProjectResult{App: apps[i], Err: err, Duration: time.Since(start)}
+			}
+		}()
+	}
+
+	for i := range apps {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// runProjectOp runs op on app, turning a panic into an error so that one
+// project can't take down the operations on the others.
+func runProjectOp(app *DdevApp, op func(app *DdevApp) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return op(app)
+}
+
+// FailedProjects returns the results of the projects whose operation failed.
+func FailedProjects(results []ProjectResult) []ProjectResult {
+	var failed []ProjectResult
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
@@ -0,0 +1,73 @@
+package ddevapp_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	asrt "github.com/stretchr/testify/assert"
+)
+
+// TestRunOnProjects makes sure RunOnProjects runs the operation on every
+// project with no more than the requested number at a time, and reports the
+// result of each in order.
+func TestRunOnProjects(t *testing.T) {
+	assert := asrt.New(t)
+
+	var apps []*ddevapp.DdevApp
+	for i := 0; i < 8; i++ {
+		apps = append(apps, &ddevapp.DdevApp{Name: fmt.Sprintf("project%d", i)})
+	}
+
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+	results := ddevapp.RunOnProjects(apps, 3, func(app *ddevapp.DdevApp) error {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+
+		switch app.Name {
+		case "project2":
+			return fmt.Errorf("failed %s", app.Name)
+		case "project5":
+			panic("panicked")
+		}
+		return nil
+	})
+
+	assert.Equal(3, maxRunning)
+	if assert.Len(results, len(apps)) {
+		for i, r := range results {
+			assert.Equal(apps[i], r.App)
+			assert.True(r.Duration >= 20*time.Millisecond)
+		}
+	}
+
+	failed := ddevapp.FailedProjects(results)
+	if assert.Len(failed, 2) {
+		assert.Equal("project2", failed[0].App.Name)
+		assert.EqualError(failed[0].Err, "failed project2")
+		assert.Equal("project5", failed[1].App.Name)
+		assert.EqualError(failed[1].Err, "panicked")
+	}
+
+	// With fewer projects than workers, or no workers requested, it still runs them all.
+	count := 0
+	results = ddevapp.RunOnProjects(apps[:2], 0, func(app *ddevapp.DdevApp) error {
+		count++
+		return nil
+	})
+	assert.Equal(2, count)
+	assert.Empty(ddevapp.FailedProjects(results))
+}
@@ -0,0 +1,93 @@
+package ddevapp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// phpExtensionRegex matches a php_extensions entry: a PECL package name,
+// optionally pinned to a version, like "redis" or "imagick@3.4.4", and
+// optionally followed by the name of the PHP module it builds when that's
+// different, like "pecl_http:http".
+var phpExtensionRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*(@[0-9][0-9A-Za-z.]*)?(:[a-z][a-z0-9_]*)?$`)
+
+// validatePHPExtensions makes sure each php_extensions entry is a valid
+// PECL package name with an optional version and module name.
+func (app *DdevApp) validatePHPExtensions() error {
+	for _, ext := range app.PHPExtensions {
+		if !phpExtensionRegex.MatchString(ext) {
+			return fmt.Errorf("invalid php_extensions entry '%s', use a PECL package name like redis, optionally with a version like imagick@3.4.4 and the module name if it's different, like pecl_http:http", ext).(invalidPHPExtension)
+		}
+	}
+	return nil
+}
+
+// phpExtensionPackage returns the PECL package to install from a
+// php_extensions entry, like "imagick-3.4.4" for "imagick@3.4.4".
+func phpExtensionPackage(ext string) string {
+	return strings.Replace(strings.SplitN(ext, ":", 2)[0], "@", "-", 1)
+}
+
+// phpExtensionName returns the name of the PHP module from a php_extensions
+// entry: the module name if given, otherwise the package name.
+func phpExtensionName(ext string) string {
+	if parts := strings.SplitN(ext, ":", 2); len(parts) == 2 {
+		return parts[1]
+	}
+	return strings.SplitN(ext, "@", 2)[0]
+}
+
+// phpExtensionsDockerfileRun returns the Dockerfile instruction that builds
+// extensions (php_extensions entries) with PECL for phpVersion (like "7.2")
+// and enables them.
+func phpExtensionsDockerfileRun(phpVersion string, extensions []string) string {
+	var packages, names, inis []string
+	for _, ext := range extensions {
+		name := phpExtensionName(ext)
+		names = append(names, name)
+		packages = append(packages, phpExtensionPackage(ext))
+		inis = append(inis, fmt.Sprintf(`echo "extension=%s.so" > /etc/php/%s/mods-available/%s.ini`, name, phpVersion, name))
+	}
+
+	// php_suffix makes pecl build with the phpize and php-config of phpVersion.
+	return fmt.Sprintf(`RUN apt-get update && DEBIAN_FRONTEND=noninteractive apt-get install -y build-essential php-pear php%s-dev && \
+  yes '' | pecl -d php_suffix=%s install -f %s && \
+  %s && \
+  phpenmod -v %s %s`, phpVersion, phpVersion, strings.Join(packages, " "), strings.Join(inis, " && \\\n  "), phpVersion, strings.Join(names, " "))
+}
+
+// missingPHPExtensions returns the php_extensions that aren't listed in
+// modules, the output of 'php -m'.
+func missingPHPExtensions(extensions []string, modules string) []string {
+	loaded := map[string]bool{}
+	for _, m := range strings.Split(modules, "\n") {
+		loaded[strings.ToLower(strings.TrimSpace(m))] = true
+	}
+	missing := []string{}
+	for _, ext := range extensions {
+		if name := phpExtensionName(ext); !loaded[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// verifyPHPExtensions checks with 'php -m' that the php_extensions are loaded
+// in the web container.
+func (app *DdevApp) verifyPHPExtensions() error {
+	if len(app.PHPExtensions) == 0 {
+		return nil
+	}
+	stdout, stderr, err := app.Exec(&ExecOpts{
+		Service: "web",
+		Cmd:     "php -m",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list PHP modules: %v, output=%s%s", err, stdout, stderr)
+	}
+	if missing := missingPHPExtensions(app.PHPExtensions, stdout); len(missing) > 0 {
+		return fmt.Errorf("php_extensions %s are not loaded by PHP %s, check the output of the web image build with 'ddev restart'", strings.Join(missing, ", "), app.GetPhpVersion())
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package ddevapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/testcommon"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPHPExtensions makes sure php_extensions are validated and built with
+// PECL for the project's PHP version in the web image.
+func TestPHPExtensions(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	app.Name = "phpextensions"
+	app.PHPVersion = ddevapp.PHP72
+
+	for _, ext := range []string{"Redis", "redis@", "redis; rm -rf /", "imagick@3.4.4@1", "pecl_http:", "pecl_http:http:x"} {
+		app.PHPExtensions = []string{ext}
+		err = app.ValidateConfig()
+		assert.Error(err, "php_extensions %s", ext)
+	}
+
+	app.WebImageExtraPackages = []string{"libmagickwand-dev"}
+	app.PHPExtensions = []string{"redis", "imagick@3.4.4", "pecl_http@3.2.0:http"}
+	assert.NoError(app.ValidateConfig())
+	_, err = app.RenderComposeYAML()
+	require.NoError(t, err)
+	dockerfile, err := ioutil.ReadFile(filepath.Join(app.GetConfigPath(".webimageExtra"), "Dockerfile"))
+	require.NoError(t, err)
+	assert.Contains(string(dockerfile), "php7.2-dev")
+	assert.Contains(string(dockerfile), "pecl -d php_suffix=7.2 install -f redis imagick-3.4.4 pecl_http-3.2.0")
+	assert.Contains(string(dockerfile), `echo "extension=imagick.so" > /etc/php/7.2/mods-available/imagick.ini`)
+	assert.Contains(string(dockerfile), `echo "extension=http.so" > /etc/php/7.2/mods-available/http.ini`)
+	assert.Contains(string(dockerfile), "phpenmod -v 7.2 redis imagick http")
+	// The extra packages providing libraries come first.
+	assert.True(strings.Index(string(dockerfile), "libmagickwand-dev") < strings.Index(string(dockerfile), "pecl"))
+}
@@ -0,0 +1,183 @@
+package ddevapp
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drud/ddev/pkg/util"
+	"github.com/drud/ddev/pkg/version"
+)
+
+// ProfilesDir is the directory in .ddev where 'ddev profile' collects profiler output
+const ProfilesDir = "profiles"
+
+// containerProfilesDir is where the xdebug profiler writes inside the web container
+const containerProfilesDir = "/tmp/ddev-profiles"
+
+// profilerConfigDir is the directory in .ddev holding the generated PHP
+// configuration for the profiler; the web container adds it to PHP_INI_SCAN_DIR.
+const profilerConfigDir = ".profiler"
+
+// writeProfilerConfig writes the PHP configuration for the project's profiler.
+func (app *DdevApp) writeProfilerConfig() error {
+	var ini string
+	switch app.Profiler {
+	case ProfilerXdebug:
+		// Profile only when asked for with the XDEBUG_PROFILE cookie or parameter.
+		ini = `xdebug.profiler_enable_trigger=1
+xdebug.profiler_output_dir=` + containerProfilesDir + `
+xdebug.profiler_output_name=cachegrind.out.%t.%p
+`
+	case ProfilerBlackfire:
+		ini = `extension=blackfire.so
+blackfire.agent_socket=tcp://blackfire:8707
+`
+	default:
+		return nil
+	}
+
+	dir := app.GetConfigPath(profilerConfigDir)
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, "ddev-profiler.ini"), []byte("; "+DdevFileSignature+"\n"+ini), 0644)
+}
+
+// blackfireDockerfileRun returns the Dockerfile instruction that installs the
+// blackfire probe for phpVersion (like "7.2") and the blackfire CLI, pinned
+// to version.BlackfireProbeVersion and to the agent's version.BlackfireTag so
+// they don't change under an existing image and stay compatible with the agent.
+func blackfireDockerfileRun(phpVersion string) string {
+	return fmt.Sprintf(`RUN curl -sSL -A "Docker" -o $(php%s -r "echo ini_get('extension_dir');")/blackfire.so https://packages.blackfire.io/binaries/blackfire-php/%s/blackfire-php-linux_amd64-php-%s.so && \
+  curl -sSL -A "Docker" -o /usr/local/bin/blackfire https://packages.blackfire.io/binaries/blackfire-agent/%s/blackfire-cli-linux_amd64 && \
+  chmod 755 /usr/local/bin/blackfire`, phpVersion, version.BlackfireProbeVersion, strings.Replace(phpVersion, ".", "", -1), version.BlackfireTag)
+}
+
+// Profile makes a profiled request to target, which is either a full URL of
+// the project or a path like "/node/1", from inside the web container.
+// With the xdebug-profile profiler the resulting cachegrind files are copied
+// into .ddev/profiles and their paths returned; with blackfire the output of
+// 'blackfire curl', which includes the link to the profile, is returned.
+func (app *DdevApp) Profile(target string) ([]string, string, error) {
+	if app.Profiler == "" {
+		return nil, "", fmt.Errorf("no profiler is configured for %s, use 'ddev config --profiler=%s' (or %s) and 'ddev restart'", app.Name, ProfilerXdebug, ProfilerBlackfire)
+	}
+	if app.SiteStatus() != SiteRunning {
+		return nil, "", fmt.Errorf("project %s is not running", app.Name)
+	}
+
+	host, path, err := app.profileTarget(target)
+	if err != nil {
+		return nil, "", err
+	}
+	// Requests go straight to the web server in the container, so they work
+	// regardless of the router, hosts file or certificates.
+	localURL := util.ShellQuote("http://127.0.0.1" + path)
+	hostHeader := util.ShellQuote("Host: " + host)
+
+	if app.Profiler == ProfilerBlackfire {
+		stdout, stderr, err := app.Exec(&ExecOpts{
+			Service: "web",
+			Cmd:     fmt.Sprintf("blackfire curl -H %s %s", hostHeader, localURL),
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("blackfire curl failed: %v, output=%s%s", err, stdout, stderr)
+		}
+		return nil, stdout + stderr, nil
+	}
+
+	// The xdebug profiler needs xdebug to be loaded; turn it on just for the request if needed.
+	xdebugActive, err := app.XdebugStatus()
+	if err != nil {
+		return nil, "", err
+	}
+	if !xdebugActive {
+		err = app.SetXdebug(true)
+		if err != nil {
+			return nil, "", err
+		}
+		defer func() {
+			if err := app.SetXdebug(false); err != nil {
+				util.Warning("Failed to disable xdebug again: %v", err)
+			}
+		}()
+	}
+
+	stdout, stderr, err := app.Exec(&ExecOpts{
+		Service: "web",
+		Cmd: fmt.Sprintf("mkdir -p %s && rm -f %s/cachegrind.out.* && curl -sS -o /dev/null -w '%%{http_code}' --cookie XDEBUG_PROFILE=1 -H %s %s && echo && ls -1 %s",
+			containerProfilesDir, containerProfilesDir, hostHeader, localURL, containerProfilesDir),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("profiled request failed: %v, output=%s%s", err, stdout, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	status := lines[0]
+	var profiles []string
+	for _, l := range lines[1:] {
+		if strings.HasPrefix(l, "cachegrind.out.") {
+			profiles = append(profiles, l)
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, "", fmt.Errorf("request to %s returned HTTP status %s but no profile was written", path, status)
+	}
+
+	web, err := app.FindContainerByType("web")
+	if err != nil || web == nil {
+		return nil, "", fmt.Errorf("unable to find web container: %v", err)
+	}
+	destDir := app.GetConfigPath(ProfilesDir)
+	err = os.MkdirAll(destDir, 0755)
+	if err != nil {
+		return nil, "", err
+	}
+	var copied []string
+	for _, p := range profiles {
+		dest := filepath.Join(destDir, p)
+		err = dockerCopy(web.ID+":"+containerProfilesDir+"/"+p, dest)
+		if err != nil {
+			return copied, "", err
+		}
+		copied = append(copied, dest)
+	}
+	return copied, fmt.Sprintf("HTTP status %s", status), nil
+}
+
+// profileTarget works out the Host header and path to request for target.
+func (app *DdevApp) profileTarget(target string) (string, string, error) {
+	if target == "" || strings.HasPrefix(target, "/") {
+		if target == "" {
+			target = "/"
+		}
+		return app.GetHostname(), target, nil
+	}
+
+	u, err := url.Parse(target)
+	if err != nil || u.Host == "" {
+		return "", "", fmt.Errorf("%s is neither a URL nor a path starting with /", target)
+	}
+	return u.Hostname(), u.RequestURI(), nil
+}
+
+// CheckProfilerCredentials warns when the blackfire profiler is configured
+// but the blackfire agent and client credentials are missing from the environment.
+func (app *DdevApp) CheckProfilerCredentials() {
+	if app.Profiler != ProfilerBlackfire {
+		return
+	}
+	var missing []string
+	for _, v := range []string{"BLACKFIRE_SERVER_ID", "BLACKFIRE_SERVER_TOKEN", "BLACKFIRE_CLIENT_ID", "BLACKFIRE_CLIENT_TOKEN"} {
+		if os.Getenv(v) == "" {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		util.Warning("The blackfire profiler needs %s set in the environment, see https://blackfire.io/my/settings/credentials", strings.Join(missing, ", "))
+	}
+}
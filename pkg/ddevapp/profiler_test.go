@@ -0,0 +1,67 @@
+package ddevapp_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevapp"
+	"github.com/drud/ddev/pkg/fileutil"
+	"github.com/drud/ddev/pkg/testcommon"
+	"github.com/drud/ddev/pkg/version"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProfilerConfig makes sure the profiler setting is validated and wires
+// the PHP configuration, web image and blackfire agent into the project.
+func TestProfilerConfig(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	require.NoError(t, err)
+	app.Name = "profilerconfig"
+
+	app.Profiler = "invalid-profiler"
+	err = app.ValidateConfig()
+	assert.Error(err)
+	assert.Contains(err.Error(), "invalid profiler")
+
+	// Without a profiler there is no blackfire service and no profiler ini.
+	app.Profiler = ""
+	assert.NoError(app.ValidateConfig())
+	content, err := app.RenderComposeYAML()
+	require.NoError(t, err)
+	assert.NotContains(content, "blackfire")
+	assert.NotContains(content, "DDEV_PROFILER")
+	assert.False(fileutil.FileExists(app.GetConfigPath(".profiler/ddev-profiler.ini")))
+
+	app.Profiler = ddevapp.ProfilerXdebug
+	assert.NoError(app.ValidateConfig())
+	content, err = app.RenderComposeYAML()
+	require.NoError(t, err)
+	assert.Contains(content, "DDEV_PROFILER="+ddevapp.ProfilerXdebug)
+	assert.NotContains(content, "ddev-${DDEV_SITENAME}-blackfire")
+	ini, err := ioutil.ReadFile(app.GetConfigPath(".profiler/ddev-profiler.ini"))
+	require.NoError(t, err)
+	assert.Contains(string(ini), "xdebug.profiler_enable_trigger=1")
+
+	app.Profiler = ddevapp.ProfilerBlackfire
+	assert.NoError(app.ValidateConfig())
+	content, err = app.RenderComposeYAML()
+	require.NoError(t, err)
+	assert.Contains(content, "ddev-${DDEV_SITENAME}-blackfire")
+	assert.Contains(content, "BLACKFIRE_SERVER_TOKEN")
+	ini, err = ioutil.ReadFile(app.GetConfigPath(".profiler/ddev-profiler.ini"))
+	require.NoError(t, err)
+	assert.Contains(string(ini), "extension=blackfire.so")
+	dockerfile, err := ioutil.ReadFile(filepath.Join(app.GetConfigPath(".webimageExtra"), "Dockerfile"))
+	require.NoError(t, err)
+	assert.Contains(string(dockerfile), "/blackfire-php/"+version.BlackfireProbeVersion+"/")
+	assert.Contains(string(dockerfile), "/blackfire-agent/"+version.BlackfireTag+"/")
+	assert.Contains(string(dockerfile), "/usr/local/bin/blackfire")
+}
@@ -3,6 +3,7 @@ package ddevapp
 import (
 	"bytes"
 	"fmt"
+	"github.com/drud/ddev/pkg/fileutil"
 	"github.com/drud/ddev/pkg/globalconfig"
 	"github.com/drud/ddev/pkg/netutil"
 	"github.com/drud/ddev/pkg/nodeps"
@@ -10,6 +11,7 @@ import (
 	"os"
 	"path"
 	"sort"
+	"sync"
 
 	"strings"
 
@@ -23,6 +25,13 @@ import (
 // RouterProjectName is the "machine name" of the router docker-compose
 const RouterProjectName = "ddev-router"
 
+// RouterDisabled is the router status reported when ddev-router is in omit_containers.
+const RouterDisabled = "disabled"
+
+// routerLock serializes changes to the router, which is shared by all
+// projects, when several projects are started or stopped at the same time.
+var routerLock sync.Mutex
+
 // RouterComposeYAMLPath returns the full filepath to the routers docker-compose yaml file.
 func RouterComposeYAMLPath() string {
 	globalDir := globalconfig.GetGlobalDdevDir()
@@ -32,6 +41,8 @@ func RouterComposeYAMLPath() string {
 
 // StopRouterIfNoContainers stops the router if there are no ddev containers running.
 func StopRouterIfNoContainers() error {
+	routerLock.Lock()
+	defer routerLock.Unlock()
 
 	containersRunning, err := ddevContainersRunning()
 	if err != nil {
@@ -40,6 +51,10 @@ func StopRouterIfNoContainers() error {
 
 	if !containersRunning {
 		dest := RouterComposeYAMLPath()
+		// If the router has never been started there is nothing to bring down.
+		if !fileutil.FileExists(dest) {
+			return nil
+		}
 		_, _, err = dockerutil.ComposeCmd([]string{dest}, "-p", RouterProjectName, "down")
 		return err
 	}
@@ -48,6 +63,9 @@ func StopRouterIfNoContainers() error {
 
 // StartDdevRouter ensures the router is running.
 func StartDdevRouter() error {
+	routerLock.Lock()
+	defer routerLock.Unlock()
+
 	newExposedPorts := determineRouterPorts()
 
 	routerComposePath := RouterComposeYAMLPath()
@@ -79,7 +97,7 @@ func StartDdevRouter() error {
 
 	err = CheckRouterPorts()
 	if err != nil {
-		return fmt.Errorf("Unable to listen on required ports, %v,\nTroubleshooting suggestions at https://ddev.readthedocs.io/en/stable/users/troubleshooting/#unable-listen", err)
+		return fmt.Errorf("Unable to listen on required ports, %v,\nYou can let ddev choose free ports with 'ddev config global --router-port-fallback=true'.\nTroubleshooting suggestions at https://ddev.readthedocs.io/en/stable/users/troubleshooting/#unable-listen", err)
 	}
 
 	// run docker-compose up -d against the ddev-router compose file
@@ -116,6 +134,9 @@ func FindDdevRouter() (*docker.APIContainers, error) {
 
 // RenderRouterStatus returns a user-friendly string showing router-status
 func RenderRouterStatus() string {
+	if nodeps.ArrayContainsString(globalconfig.DdevGlobalConfig.OmitContainers, RouterContainer) {
+		return fmt.Sprintf("\nDDEV ROUTER STATUS: %v", RouterDisabled)
+	}
 	status, logOutput := GetRouterStatus()
 	var renderedStatus string
 	badRouter := "\nThe router is not yet healthy. Your projects may not be accessible.\nIf it doesn't become healthy try running 'ddev start' on a project to recreate it."
@@ -232,3 +253,69 @@ func CheckRouterPorts() error {
 	}
 	return nil
 }
+
+// IsRouterPortAvailable returns true if the port is either already exposed by
+// the ddev-router or is not in use by anything else.
+func IsRouterPortAvailable(port string) bool {
+	routerContainer, _ := FindDdevRouter()
+	if routerContainer != nil {
+		existingExposedPorts, err := dockerutil.GetExposedContainerPorts(routerContainer.ID)
+		if err == nil && nodeps.ArrayContainsString(existingExposedPorts, port) {
+			return true
+		}
+	}
+	return !netutil.IsPortActive(port)
+}
+
+// UseFallbackRouterPorts replaces the project's router_http_port and router_https_port
+// with free ports if they are occupied by something other than the ddev-router, and
+// persists the new ports in the project's config.yaml.
+// It does nothing unless router_port_fallback is enabled in the global config.
+func (app *DdevApp) UseFallbackRouterPorts() error {
+	if !globalconfig.DdevGlobalConfig.RouterPortFallback || app.IsRouterDisabled() {
+		return nil
+	}
+
+	dockerIP, err := dockerutil.GetDockerIP()
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	for _, port := range []*string{&app.RouterHTTPPort, &app.RouterHTTPSPort} {
+		if IsRouterPortAvailable(*port) {
+			continue
+		}
+		var newPort string
+		// Make sure http and https don't end up on the same port.
+		for newPort == "" || newPort == app.RouterHTTPPort || newPort == app.RouterHTTPSPort {
+			newPort, err = globalconfig.GetFreePort(dockerIP)
+			if err != nil {
+				return err
+			}
+		}
+		util.Warning("Router port %s is already in use, using port %s instead", *port, newPort)
+		*port = newPort
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+
+	// Persist only the new ports; reading the config without overrides makes sure
+	// config.*.yaml values don't get written into config.yaml.
+	configApp, err := NewApp(app.AppRoot, false, app.Provider)
+	if err != nil {
+		return err
+	}
+	configApp.RouterHTTPPort = app.RouterHTTPPort
+	configApp.RouterHTTPSPort = app.RouterHTTPSPort
+	err = configApp.WriteConfig()
+	if err != nil {
+		return fmt.Errorf("failed to save fallback router ports to %s: %v", app.ConfigPath, err)
+	}
+
+	// The router ports are passed to docker-compose through the environment.
+	app.DockerEnv()
+	return nil
+}
@@ -2,7 +2,12 @@ package ddevapp_test
 
 import (
 	"github.com/drud/ddev/pkg/exec"
+	"github.com/drud/ddev/pkg/globalconfig"
 	"github.com/drud/ddev/pkg/netutil"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net"
+	"path/filepath"
 	"strconv"
 	"testing"
 
@@ -87,3 +92,110 @@ func TestPortOverride(t *testing.T) {
 	}
 
 }
+
+// TestRouterDisabled makes sure that a project with ddev-router in omit_containers
+// starts without touching the router and is reachable on its direct URL.
+func TestRouterDisabled(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	testcommon.ClearDockerEnv()
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	assert.NoError(err)
+	app.Name = t.Name()
+	app.Type = ddevapp.AppTypePHP
+	app.OmitContainers = []string{ddevapp.RouterContainer}
+	err = app.WriteConfig()
+	assert.NoError(err)
+	assert.True(app.IsRouterDisabled())
+
+	err = ioutil.WriteFile(filepath.Join(testDir, "index.php"), []byte("<?php\necho 'hello from the web container';\n"), 0644)
+	assert.NoError(err)
+
+	// Before the web container publishes its ports there are no URLs.
+	assert.Empty(app.GetHTTPURL())
+	assert.Empty(app.GetHTTPSURL())
+	assert.Empty(app.GetAllURLs())
+	desc, err := app.Describe()
+	assert.NoError(err)
+	assert.Empty(desc["httpurl"])
+	assert.Empty(desc["urls"])
+
+	err = app.Start()
+	// nolint: errcheck
+	defer app.Stop(true, false)
+	require.NoError(t, err)
+
+	// The generated docker-compose.yaml must not link to or advertise the router.
+	found, err := fileutil.FgrepStringInFile(app.DockerComposeYAMLPath(), "external_links")
+	assert.NoError(err)
+	assert.False(found)
+	found, err = fileutil.FgrepStringInFile(app.DockerComposeYAMLPath(), "HTTP_EXPOSE")
+	assert.NoError(err)
+	assert.False(found)
+
+	// All URLs should be direct web container URLs.
+	assert.Equal(app.GetWebContainerDirectURL(), app.GetHTTPURL())
+	for _, url := range app.GetAllURLs() {
+		assert.NotContains(url, app.GetHostname())
+	}
+
+	assert.NotEmpty(app.GetAllURLs())
+	desc, err = app.Describe()
+	assert.NoError(err)
+	assert.Equal(ddevapp.RouterDisabled, desc["router_status"])
+
+	_, err = testcommon.EnsureLocalHTTPContent(t, app.GetHTTPURL(), "hello from the web container")
+	assert.NoError(err)
+}
+
+// TestRouterPortFallback makes sure that with router_port_fallback enabled a
+// project whose router_http_port is occupied gets a free port instead, and that
+// the new port is saved in config.yaml.
+func TestRouterPortFallback(t *testing.T) {
+	assert := asrt.New(t)
+
+	testDir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(testDir)
+	defer testcommon.Chdir(testDir)()
+
+	testcommon.ClearDockerEnv()
+
+	origFallback := globalconfig.DdevGlobalConfig.RouterPortFallback
+	globalconfig.DdevGlobalConfig.RouterPortFallback = true
+	defer func() {
+		globalconfig.DdevGlobalConfig.RouterPortFallback = origFallback
+	}()
+
+	// Occupy a port so the router can't use it.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	// nolint: errcheck
+	defer listener.Close()
+	_, busyPort, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+
+	app, err := ddevapp.NewApp(testDir, true, ddevapp.ProviderDefault)
+	assert.NoError(err)
+	app.Name = t.Name()
+	app.Type = ddevapp.AppTypePHP
+	app.RouterHTTPPort = busyPort
+	err = app.WriteConfig()
+	assert.NoError(err)
+
+	err = app.Start()
+	// nolint: errcheck
+	defer app.Stop(true, false)
+	require.NoError(t, err)
+
+	assert.NotEqual(busyPort, app.RouterHTTPPort)
+	assert.Contains(app.GetHTTPURL(), ":"+app.RouterHTTPPort)
+	found, err := fileutil.FgrepStringInFile(app.ConfigPath, "router_http_port: \""+app.RouterHTTPPort+"\"")
+	assert.NoError(err)
+	assert.True(found)
+	assert.True(netutil.IsPortActive(app.RouterHTTPPort))
+}
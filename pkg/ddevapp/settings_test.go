@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"os"
+	"regexp"
+	"strings"
 
 	"io/ioutil"
 
@@ -43,6 +45,8 @@ func TestWriteSettings(t *testing.T) {
 		AppTypeDrupal8:   "sites/default/settings.ddev.php",
 		AppTypeWordPress: "wp-config-ddev.php",
 		AppTypeTYPO3:     "typo3conf/AdditionalConfiguration.php",
+		AppTypeLaravel:   ".env",
+		AppTypeMagento2:  "app/etc/env.php",
 	}
 	dir := testcommon.CreateTmpDir(t.Name())
 
@@ -371,3 +375,180 @@ func TestDrupalBackdropOverwriteDdevSettings(t *testing.T) {
 		assert.True(containsOriginalString, "Did not find %s in the settings file; it should have still been there", originalContents)
 	}
 }
+
+// TestLaravelEnvSettings ensures the ddev settings are written into an existing
+// Laravel .env without disturbing the project's other settings.
+func TestLaravelEnvSettings(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(dir)
+
+	app, err := NewApp(dir, true, ProviderDefault)
+	assert.NoError(err)
+	app.Type = AppTypeLaravel
+
+	// Without a .env, it is created from .env.example.
+	err = ioutil.WriteFile(filepath.Join(dir, ".env.example"), []byte("APP_NAME=Example\nDB_HOST=127.0.0.1\nMAIL_MAILER=log\n"), 0644)
+	require.NoError(t, err)
+	envFile, err := app.CreateSettingsFile()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(dir, ".env"), envFile)
+
+	contents, err := ioutil.ReadFile(envFile)
+	require.NoError(t, err)
+	assert.Contains(string(contents), "APP_NAME=Example\n")
+	assert.Contains(string(contents), "DB_HOST=db\n")
+	assert.NotContains(string(contents), "DB_HOST=127.0.0.1")
+	assert.Contains(string(contents), "DB_DATABASE=db\n")
+	assert.Contains(string(contents), "MAIL_MAILER=smtp\n")
+	assert.NotContains(string(contents), "MAIL_DRIVER")
+	assert.Contains(string(contents), "MAIL_PORT=1025\n")
+	assert.Contains(string(contents), "APP_URL="+app.GetHTTPSURL()+"\n")
+
+	// Rewriting an existing .env replaces values in place rather than adding them again.
+	err = ioutil.WriteFile(envFile, []byte("APP_KEY=base64:secret\nDB_PASSWORD=\"secret\"\n"), 0644)
+	require.NoError(t, err)
+	_, err = app.CreateSettingsFile()
+	assert.NoError(err)
+	_, err = app.CreateSettingsFile()
+	assert.NoError(err)
+	contents, err = ioutil.ReadFile(envFile)
+	require.NoError(t, err)
+	assert.Contains(string(contents), "APP_KEY=base64:secret\n")
+	assert.Contains(string(contents), "DB_PASSWORD=db\n")
+	assert.Contains(string(contents), "MAIL_DRIVER=smtp\n")
+	assert.Equal(1, strings.Count(string(contents), "DB_HOST="))
+	assert.Equal(1, strings.Count(string(contents), DdevFileSignature))
+
+	// ddev doesn't write a .gitignore into the project root.
+	assert.False(fileutil.FileExists(filepath.Join(dir, ".gitignore")))
+
+	// A .env that's only readable by its owner stays that way.
+	err = os.Chmod(envFile, 0600)
+	require.NoError(t, err)
+	_, err = app.CreateSettingsFile()
+	assert.NoError(err)
+	fi, err := os.Stat(envFile)
+	require.NoError(t, err)
+	assert.Equal(os.FileMode(0600), fi.Mode().Perm())
+}
+
+// TestMagento2EnvSettings ensures env.php gets the ddev database and base URLs,
+// keeps its crypt key when rewritten, and is left alone when user-managed.
+func TestMagento2EnvSettings(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(dir)
+
+	app, err := NewApp(dir, true, ProviderDefault)
+	assert.NoError(err)
+	app.Type = AppTypeMagento2
+
+	envFile, err := app.CreateSettingsFile()
+	assert.NoError(err)
+	assert.Equal(filepath.Join(dir, "app", "etc", "env.php"), envFile)
+	contents, err := ioutil.ReadFile(envFile)
+	require.NoError(t, err)
+	assert.Contains(string(contents), "'dbname' => 'db'")
+	assert.Contains(string(contents), "'base_url' => '"+app.GetAllURLs()[0]+"/'")
+
+	// The crypt key survives rewriting the file.
+	cryptKey := regexp.MustCompile(`'key' => '([^']+)'`).FindStringSubmatch(string(contents))
+	require.Len(t, cryptKey, 2)
+	_, err = app.CreateSettingsFile()
+	assert.NoError(err)
+	contents, err = ioutil.ReadFile(envFile)
+	require.NoError(t, err)
+	assert.Contains(string(contents), "'key' => '"+cryptKey[1]+"'")
+
+	// A user-managed env.php is not touched.
+	userContents := "<?php\nreturn [];\n"
+	err = ioutil.WriteFile(envFile, []byte(userContents), 0644)
+	require.NoError(t, err)
+	_, err = app.CreateSettingsFile()
+	assert.NoError(err)
+	contents, err = ioutil.ReadFile(envFile)
+	require.NoError(t, err)
+	assert.Equal(userContents, string(contents))
+}
+
+// TestDrupalMultisiteSettings ensures each configured multisite gets its own
+// settings with its own database, a sites.php entry and a hostname.
+func TestDrupalMultisiteSettings(t *testing.T) {
+	assert := asrt.New(t)
+
+	dir := testcommon.CreateTmpDir(t.Name())
+	defer testcommon.CleanupDir(dir)
+
+	app, err := NewApp(dir, true, ProviderDefault)
+	assert.NoError(err)
+	app.Name = "multisite"
+	app.Type = AppTypeDrupal8
+	app.DrupalMultisites = []DrupalMultisite{
+		{SiteDir: "site1"},
+		{SiteDir: "second.example.com", Database: "second", Hostname: "second"},
+	}
+	assert.NoError(app.ValidateConfig())
+	assert.Contains(app.GetHostnames(), "site1."+app.ProjectTLD)
+	assert.Contains(app.GetHostnames(), "second."+app.ProjectTLD)
+
+	_, err = app.CreateSettingsFile()
+	assert.NoError(err)
+
+	for siteDir, database := range map[string]string{"default": "db", "site1": "site1", "second.example.com": "second"} {
+		settingsDdev := filepath.Join(dir, "sites", siteDir, "settings.ddev.php")
+		found, err := fileutil.FgrepStringInFile(settingsDdev, `'database' => "`+database+`"`)
+		assert.NoError(err)
+		assert.True(found, "%s does not use database %s", settingsDdev, database)
+		found, err = fileutil.FgrepStringInFile(filepath.Join(dir, "sites", siteDir, "settings.php"), "settings.ddev.php")
+		assert.NoError(err)
+		assert.True(found)
+	}
+
+	sitesDdev, err := ioutil.ReadFile(filepath.Join(dir, "sites", "sites.ddev.php"))
+	require.NoError(t, err)
+	assert.Contains(string(sitesDdev), "$sites['site1."+app.ProjectTLD+"'] = 'site1';")
+	assert.Contains(string(sitesDdev), "$sites['second."+app.ProjectTLD+"'] = 'second.example.com';")
+	found, err := fileutil.FgrepStringInFile(filepath.Join(dir, "sites", "sites.php"), "sites.ddev.php")
+	assert.NoError(err)
+	assert.True(found)
+
+	// An existing sites.php is kept and gets the include appended just once.
+	err = ioutil.WriteFile(filepath.Join(dir, "sites", "sites.php"), []byte("<?php\n$sites['example.com'] = 'site1';\n"), 0644)
+	require.NoError(t, err)
+	_, err = app.CreateSettingsFile()
+	assert.NoError(err)
+	_, err = app.CreateSettingsFile()
+	assert.NoError(err)
+	sitesPHP, err := ioutil.ReadFile(filepath.Join(dir, "sites", "sites.php"))
+	require.NoError(t, err)
+	assert.Contains(string(sitesPHP), "$sites['example.com'] = 'site1';")
+	assert.Equal(2, strings.Count(string(sitesPHP), "sites.ddev.php"))
+
+	// Databases must be distinct, and multisites only apply to Drupal.
+	app.DrupalMultisites = append(app.DrupalMultisites, DrupalMultisite{SiteDir: "third", Database: "site1"})
+	assert.Error(app.ValidateConfig())
+	app.DrupalMultisites = app.DrupalMultisites[:2]
+
+	// Hostnames must be valid, distinct, and not one of the project's own.
+	for _, extra := range [][]DrupalMultisite{
+		{{SiteDir: "a.b"}, {SiteDir: "a_b"}},
+		{{SiteDir: "third", Hostname: "second"}},
+		{{SiteDir: "third", Hostname: "multisite"}},
+		{{SiteDir: "third", Hostname: "x';phpinfo();'"}},
+		{{SiteDir: "third", Hostname: "bad_host"}},
+	} {
+		app.DrupalMultisites = append(app.DrupalMultisites, extra...)
+		assert.Error(app.ValidateConfig(), "hostname %s should be rejected", extra[len(extra)-1].GetHostname())
+		app.DrupalMultisites = app.DrupalMultisites[:2]
+	}
+	app.AdditionalHostnames = []string{"site1"}
+	assert.Error(app.ValidateConfig())
+	app.AdditionalHostnames = nil
+	assert.NoError(app.ValidateConfig())
+
+	app.Type = AppTypeWordPress
+	assert.Error(app.ValidateConfig())
+}
@@ -37,6 +37,9 @@ services:
     environment:
       - COLUMNS=$COLUMNS
       - LINES=$LINES
+      {{ range .DBEnvironment }}
+      - {{ . }}
+      {{ end }}
     command: "$DDEV_MARIADB_LOCAL_COMMAND"
     healthcheck:
       interval: 5s
@@ -87,19 +90,33 @@ services:
       - DDEV_ROUTER_HTTP_PORT=$DDEV_ROUTER_HTTP_PORT
       - DDEV_ROUTER_HTTPS_PORT=$DDEV_ROUTER_HTTPS_PORT
       - DDEV_XDEBUG_ENABLED=$DDEV_XDEBUG_ENABLED
+      {{ if .Profiler }}
+      - DDEV_PROFILER={{ .Profiler }}
+      # The leading colon keeps the normal conf.d directory and adds the profiler one
+      - PHP_INI_SCAN_DIR=:/mnt/ddev_config/.profiler
+      {{ end }}
+      {{ if eq .Profiler "blackfire" }}
+      - BLACKFIRE_CLIENT_ID=${BLACKFIRE_CLIENT_ID}
+      - BLACKFIRE_CLIENT_TOKEN=${BLACKFIRE_CLIENT_TOKEN}
+      {{ end }}
       - DOCKER_IP={{ .DockerIP }}
       - HOST_DOCKER_INTERNAL_IP={{ .HostDockerInternalIP }}
       - DEPLOY_NAME=local
-      - VIRTUAL_HOST=$DDEV_HOSTNAME
       - COLUMNS=$COLUMNS
       - LINES=$LINES
+      {{ if not .OmitRouter }}
+      - VIRTUAL_HOST=$DDEV_HOSTNAME
       # HTTP_EXPOSE allows for ports accepting HTTP traffic to be accessible from <site>.ddev.site:<port>
       # To expose a container port to a different host port, define the port as hostPort:containerPort
       - HTTP_EXPOSE=${DDEV_ROUTER_HTTP_PORT}:80,${DDEV_MAILHOG_PORT}:{{ .MailhogPort }}
       # You can optionally expose an HTTPS port option for any ports defined in HTTP_EXPOSE.
       # To expose an HTTPS port, define the port as securePort:containerPort.
       - HTTPS_EXPOSE=${DDEV_ROUTER_HTTPS_PORT}:80
+      {{ end }}
       - SSH_AUTH_SOCK=/home/.ssh-agent/socket
+      {{ range .WebEnvironment }}
+      - {{ . }}
+      {{ end }}
     labels:
       com.ddev.site-name: ${DDEV_SITENAME}
       com.ddev.platform: {{ .Plugin }}
@@ -108,9 +125,11 @@ services:
 {{ if .HostDockerInternalIP }}
     extra_hosts: [ "host.docker.internal:{{ .HostDockerInternalIP }}" ]
 {{ end }}
+{{ if not .OmitRouter }}
     external_links:
     {{ range $hostname := .Hostnames }}- "ddev-router:{{ $hostname }}" 
     {{ end }}
+{{ end }}
     healthcheck:
       interval: 4s
       retries: 6
@@ -161,14 +180,31 @@ services:
     environment:
       - PMA_USER=db
       - PMA_PASSWORD=db
+      {{ if not .OmitRouter }}
       - VIRTUAL_HOST=$DDEV_HOSTNAME
       # HTTP_EXPOSE allows for ports accepting HTTP traffic to be accessible from <site>.ddev.site:<port>
       - HTTP_EXPOSE=${DDEV_PHPMYADMIN_PORT}:{{ .DBAPort }}
+      {{ end }}
     healthcheck:
       interval: 90s
       timeout: 2s
       retries: 1
 
+{{end}}
+{{ if eq .Profiler "blackfire" }}
+  blackfire:
+    container_name: ddev-${DDEV_SITENAME}-blackfire
+    image: {{ .BlackfireImage }}
+    restart: "no"
+    labels:
+      com.ddev.site-name: ${DDEV_SITENAME}
+      com.ddev.platform: {{ .Plugin }}
+      com.ddev.app-type: {{ .AppType }}
+      com.ddev.approot: $DDEV_APPROOT
+    environment:
+      - BLACKFIRE_SERVER_ID=${BLACKFIRE_SERVER_ID}
+      - BLACKFIRE_SERVER_TOKEN=${BLACKFIRE_SERVER_TOKEN}
+
 {{end}}
 networks:
   default:
@@ -205,7 +241,7 @@ const ConfigInstructions = `
 # name: <projectname> # Name of the project, automatically provides
 #   http://projectname.ddev.site and https://projectname.ddev.site
 
-# type: <projecttype>  # drupal6/7/8, backdrop, typo3, wordpress, php
+# type: <projecttype>  # drupal6/7/8, backdrop, typo3, wordpress, laravel, magento2, php
 
 # docroot: <relative_path> # Relative path to the directory containing index.php.
 
@@ -225,6 +261,16 @@ const ConfigInstructions = `
 
 # xdebug_enabled: false  # Set to true to enable xdebug and "ddev start" or "ddev restart"
 
+# profiler: xdebug-profile  # or blackfire; enables "ddev profile <url>"
+# blackfire uses the BLACKFIRE_SERVER_ID, BLACKFIRE_SERVER_TOKEN,
+# BLACKFIRE_CLIENT_ID and BLACKFIRE_CLIENT_TOKEN environment variables.
+
+# nodejs_version: "10"  # Node.js major version in the web container, "8", "10" or "12",
+# used by "ddev npm" and "ddev yarn". Changing it rebuilds the web image on "ddev start".
+
+# composer_version: "1"  # composer in the web container: "1" or "2" for the latest
+# of that major version, or a specific version like "1.10.1"
+
 # webserver_type: nginx-fpm  # Can be set to apache-fpm or apache-cgi as well
 
 # additional_hostnames:
@@ -239,6 +285,14 @@ const ConfigInstructions = `
 # would provide http and https URLs for "example.com" and "sub1.example.com"
 # Please take care with this because it can cause great confusion.
 
+# drupal_multisites:
+#  - site_dir: site1
+#    database: site1
+#    hostname: site1
+# would set up the Drupal multisite in sites/site1 with its own database "site1"
+# (created on ddev start) and hostname "site1.ddev.site", writing its
+# settings.ddev.php and a sites.php entry. database and hostname default to site_dir.
+
 # upload_dir: custom/upload/dir
 # would set the destination path for ddev import-files to custom/upload/dir.
 
@@ -251,7 +305,9 @@ const ConfigInstructions = `
 
 # omit_containers: ["dba", "ddev-ssh-agent"]
 # would omit the dba (phpMyAdmin) and ddev-ssh-agent containers. Currently
-# only those two containers can be omitted here.
+# only those two containers and "ddev-router" can be omitted here.
+# Omitting "ddev-router" means the project is reached only through the
+# web container's published ports (see "ddev describe"), not its hostnames.
 # Note that these containers can also be omitted globally in the 
 # ~/.ddev/global_config.yaml or with the "ddev config global" command.
 
@@ -289,6 +345,21 @@ const ConfigInstructions = `
 # Extra Debian packages that are needed in the webimage can be added here
 # This is ignored if a free-form .ddev/web-build/Dockerfile is provided
 
+# php_extensions: [redis, imagick@3.4.4]
+# PHP extensions built with PECL for the project's php_version and enabled,
+# optionally pinned to a version. Add the module name if it's not the
+# package name, like pecl_http:http. Libraries they need can be added with
+# webimage_extra_packages, like libmagickwand-dev for imagick.
+# This is ignored if a free-form .ddev/web-build/Dockerfile is provided
+
+# web_environment:
+# - API_KEY=1234
+# db_environment:
+# - SOME_FLAG=1
+# Environment variables for the web and db containers, added to the ones
+# in .ddev/project.env, a file of KEY=value lines. They're also set for
+# exec-host hooks. Restart the project after changing them.
+
 # dbimage_extra_packages: [telnet,netcat]
 # Extra Debian packages that are needed in the dbimage can be added here
 # This is ignored if a free-form .ddev/db-build/Dockerfile is provided
@@ -134,12 +134,9 @@ func setTypo3SiteSettingsPaths(app *DdevApp) {
 	app.SiteDdevSettingsFile = localSettingsFilePath
 }
 
-// isTypoApp returns true if the app is of type typo3
-func isTypo3App(app *DdevApp) bool {
-	if _, err := os.Stat(filepath.Join(app.AppRoot, app.Docroot, "typo3")); err == nil {
-		return true
-	}
-	return false
+// detectTypo3App returns the files showing the app is of type typo3.
+func detectTypo3App(app *DdevApp) []string {
+	return app.existingFiles(app.Docroot, "typo3")
 }
 
 // typo3ConfigOverrideAction sets a safe php_version for TYPO3
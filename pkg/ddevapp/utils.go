@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/drud/ddev/pkg/globalconfig"
 	"github.com/drud/ddev/pkg/nodeps"
+	"io/ioutil"
 	"path"
 	"path/filepath"
 	"sort"
@@ -23,6 +24,7 @@ import (
 	"github.com/drud/ddev/pkg/output"
 	"github.com/drud/ddev/pkg/util"
 	gohomedir "github.com/mitchellh/go-homedir"
+	"gopkg.in/yaml.v2"
 )
 
 // GetActiveProjects returns an array of ddev projects
@@ -59,13 +61,26 @@ func GetActiveProjects() []*DdevApp {
 	return apps
 }
 
+// DefaultListColumns are the columns of the app table for describe and list output.
+var DefaultListColumns = []string{"name", "type", "location", "url", "status"}
+
 // CreateAppTable will create a new app table for describe and list output
 func CreateAppTable() *uitable.Table {
+	return CreateAppTableWithColumns(DefaultListColumns)
+}
+
+// CreateAppTableWithColumns will create a new app table with the given
+// columns, which must be in ValidListColumns.
+func CreateAppTableWithColumns(columns []string) *uitable.Table {
 	table := uitable.New()
 	table.MaxColWidth = 140
 	table.Separator = "  "
 	table.Wrap = true
-	table.AddRow("NAME", "TYPE", "LOCATION", "URL", "STATUS")
+	var headers []interface{}
+	for _, column := range columns {
+		headers = append(headers, strings.ToUpper(strings.Replace(column, "_", " ", -1)))
+	}
+	table.AddRow(headers...)
 	return table
 }
 
@@ -80,42 +95,59 @@ func RenderHomeRootedDir(path string) string {
 
 // RenderAppRow will add an application row to an existing table for describe and list output.
 func RenderAppRow(table *uitable.Table, row map[string]interface{}) {
-	status := fmt.Sprint(row["status"])
-
-	switch {
-	case strings.Contains(status, SitePaused):
-		status = color.YellowString(status)
-	case strings.Contains(status, SiteStopped):
-		status = color.RedString(status)
-	case strings.Contains(status, SiteDirMissing):
-		status = color.RedString(status)
-	case strings.Contains(status, SiteConfigMissing):
-		status = color.RedString(status)
-	default:
-		status = color.CyanString(status)
-	}
-	syncStatus := row["sync_status"].(string)
-	if syncStatus != "" {
-		status = status + "\n" + syncStatus
+	RenderAppRowColumns(table, row, DefaultListColumns)
+}
+
+// RenderAppRowColumns will add an application row with the given columns to
+// a table created by CreateAppTableWithColumns.
+func RenderAppRowColumns(table *uitable.Table, row map[string]interface{}, columns []string) {
+	var values []interface{}
+	for _, column := range columns {
+		values = append(values, renderAppColumn(row, column))
 	}
+	table.AddRow(values...)
+}
 
-	urls := ""
-	if row["status"] == SiteRunning {
+// renderAppColumn renders one column of an application row.
+func renderAppColumn(row map[string]interface{}, column string) interface{} {
+	switch column {
+	case "location":
+		return row["shortroot"]
+	case "url":
+		if row["status"] != SiteRunning {
+			return ""
+		}
 		if GetCAROOT() != "" {
-			urls = row["httpsurl"].(string)
-		} else {
-			urls = row["httpurl"].(string)
+			return row["httpsurl"]
 		}
+		return row["httpurl"]
+	case "urls", "hostnames":
+		if values, ok := row[column].([]string); ok {
+			return strings.Join(values, "\n")
+		}
+		return ""
+	case "status":
+		status := fmt.Sprint(row["status"])
+
+		switch {
+		case strings.Contains(status, SitePaused):
+			status = color.YellowString(status)
+		case strings.Contains(status, SiteStopped):
+			status = color.RedString(status)
+		case strings.Contains(status, SiteDirMissing):
+			status = color.RedString(status)
+		case strings.Contains(status, SiteConfigMissing):
+			status = color.RedString(status)
+		default:
+			status = color.CyanString(status)
+		}
+		if syncStatus, _ := row["sync_status"].(string); syncStatus != "" {
+			status = status + "\n" + syncStatus
+		}
+		return status
+	default:
+		return row[column]
 	}
-
-	table.AddRow(
-		row["name"],
-		row["type"],
-		row["shortroot"],
-		urls,
-		status,
-	)
-
 }
 
 // Cleanup will remove ddev containers and volumes even if docker-compose.yml
@@ -377,3 +409,55 @@ func GetProjects(activeOnly bool) ([]*DdevApp, error) {
 
 	return appSlice, nil
 }
+
+// readProjectTLD returns the project_tld of the project in appRoot from its
+// config.yaml and config.*.yaml files, the last one setting it winning, or
+// "" if none of them does.
+func readProjectTLD(appRoot string) string {
+	app := &DdevApp{ConfigPath: filepath.Join(appRoot, ".ddev", "config.yaml")}
+	overrides, err := app.configOverrideFiles()
+	if err != nil {
+		return ""
+	}
+	tld := ""
+	for _, file := range append([]string{app.ConfigPath}, overrides...) {
+		source, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		source, _, err = interpolateConfigEnv(source)
+		if err != nil {
+			continue
+		}
+		var config struct {
+			ProjectTLD string `yaml:"project_tld"`
+		}
+		if err = yaml.Unmarshal(source, &config); err == nil && config.ProjectTLD != "" {
+			tld = config.ProjectTLD
+		}
+	}
+	return tld
+}
+
+// GetProjectTLDs returns the default project TLD plus the project_tld of every
+// project in the global project list, sorted and without duplicates.
+// It doesn't need docker, so it can be used while offline, and only reads
+// project_tld from the config files, so it's cheap enough to call often.
+func GetProjectTLDs() []string {
+	tlds := map[string]bool{DdevDefaultTLD: true}
+	for _, info := range globalconfig.GetGlobalProjectList() {
+		if info.AppRoot == "" {
+			continue
+		}
+		if tld := readProjectTLD(info.AppRoot); tld != "" {
+			tlds[tld] = true
+		}
+	}
+
+	result := []string{}
+	for tld := range tlds {
+		result = append(result, tld)
+	}
+	sort.Strings(result)
+	return result
+}
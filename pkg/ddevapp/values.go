@@ -70,6 +70,7 @@ const (
 var ValidOmitContainers = map[string]bool{
 	DdevSSHAgentContainer: true,
 	DBAContainer:          true,
+	RouterContainer:       true,
 }
 
 // WebserverDefault is the default webserver type, overridden by $DDEV_WEBSERVER_TYPE
@@ -89,12 +90,63 @@ var ValidWebserverTypes = map[string]bool{
 	WebserverApacheCGI: true,
 }
 
+// Profilers
+const (
+	ProfilerXdebug    = "xdebug-profile"
+	ProfilerBlackfire = "blackfire"
+)
+
+// ValidProfilers should be updated whenever supported profilers are added or
+// removed, and should be used to ensure user-supplied values are valid.
+var ValidProfilers = map[string]bool{
+	ProfilerXdebug:    true,
+	ProfilerBlackfire: true,
+}
+
+// ValidNodeJSVersions should be updated whenever supported Node.js major versions
+// are added or removed, and should be used to ensure user-supplied values are valid.
+var ValidNodeJSVersions = map[string]bool{
+	"8":  true,
+	"10": true,
+	"12": true,
+}
+
+// List sort keys
+const (
+	ListSortName   = "name"
+	ListSortStatus = "status"
+	ListSortType   = "type"
+)
+
+// ValidListSorts are the keys ddev list can sort projects by.
+var ValidListSorts = map[string]bool{
+	ListSortName:   true,
+	ListSortStatus: true,
+	ListSortType:   true,
+}
+
+// ValidListColumns are the columns ddev list can show; each is rendered by
+// RenderAppRowColumns from the project description.
+var ValidListColumns = map[string]bool{
+	"name":        true,
+	"type":        true,
+	"location":    true,
+	"approot":     true,
+	"url":         true,
+	"urls":        true,
+	"hostnames":   true,
+	"status":      true,
+	"php_version": true,
+}
+
 // App types
 const (
 	AppTypeBackdrop  = "backdrop"
 	AppTypeDrupal6   = "drupal6"
 	AppTypeDrupal7   = "drupal7"
 	AppTypeDrupal8   = "drupal8"
+	AppTypeLaravel   = "laravel"
+	AppTypeMagento2  = "magento2"
 	AppTypePHP       = "php"
 	AppTypeTYPO3     = "typo3"
 	AppTypeWordPress = "wordpress"
@@ -238,3 +290,97 @@ func GetValidOmitContainers() []string {
 
 	return s
 }
+
+// IsValidProfiler is a helper function to determine if a profiler is valid, returning
+// true if the supplied profiler is valid or empty (no profiler) and false otherwise.
+func IsValidProfiler(profiler string) bool {
+	if profiler == "" {
+		return true
+	}
+	if _, ok := ValidProfilers[profiler]; !ok {
+		return false
+	}
+
+	return true
+}
+
+// GetValidProfilers is a helper function that returns a list of valid profilers.
+func GetValidProfilers() []string {
+	s := make([]string, 0, len(ValidProfilers))
+
+	for p := range ValidProfilers {
+		s = append(s, p)
+	}
+
+	return s
+}
+
+// IsValidNodeJSVersion is a helper function to determine if a Node.js version is valid, returning
+// true if the supplied version is valid or empty (the web image's own Node.js) and false otherwise.
+func IsValidNodeJSVersion(version string) bool {
+	if version == "" {
+		return true
+	}
+	if _, ok := ValidNodeJSVersions[version]; !ok {
+		return false
+	}
+
+	return true
+}
+
+// GetValidNodeJSVersions is a helper function that returns a list of valid Node.js versions.
+func GetValidNodeJSVersions() []string {
+	s := make([]string, 0, len(ValidNodeJSVersions))
+
+	for v := range ValidNodeJSVersions {
+		s = append(s, v)
+	}
+
+	return s
+}
+
+// IsValidListSort is a helper function to determine if a ddev list sort key
+// is valid, returning true if the supplied key is valid or empty (unsorted)
+// and false otherwise.
+func IsValidListSort(sort string) bool {
+	if sort == "" {
+		return true
+	}
+	if _, ok := ValidListSorts[sort]; !ok {
+		return false
+	}
+
+	return true
+}
+
+// GetValidListSorts is a helper function that returns a list of valid ddev list sort keys.
+func GetValidListSorts() []string {
+	s := make([]string, 0, len(ValidListSorts))
+
+	for k := range ValidListSorts {
+		s = append(s, k)
+	}
+
+	return s
+}
+
+// IsValidListColumn is a helper function to determine if a ddev list column
+// is valid, returning true if the supplied column is valid and false otherwise.
+func IsValidListColumn(column string) bool {
+	if _, ok := ValidListColumns[column]; !ok {
+		return false
+	}
+
+	return true
+}
+
+// GetValidListColumns is a helper function that returns a list of valid ddev list columns.
+func GetValidListColumns() []string {
+	s := make([]string, 0, len(ValidListColumns))
+
+	for c := range ValidListColumns {
+		s = append(s, c)
+	}
+
+	return s
+}
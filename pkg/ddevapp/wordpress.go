@@ -46,7 +46,7 @@ func NewWordpressConfig(app *DdevApp, absPath string) *WordpressConfig {
 		DatabaseUsername: "db",
 		DatabasePassword: "db",
 		DatabaseHost:     "db",
-		DeployURL:        app.GetHTTPURL(),
+		DeployURL:        app.getSettingsURL(false),
 		Docroot:          "/var/www/html/docroot",
 		TablePrefix:      "wp_",
 		AuthKey:          util.RandString(64),
@@ -295,20 +295,14 @@ func setWordpressSiteSettingsPaths(app *DdevApp) {
 	app.SiteDdevSettingsFile = filepath.Join(settingsFileBasePath, config.SiteSettingsDdev)
 }
 
-// isWordpressApp returns true if the app of of type wordpress
-func isWordpressApp(app *DdevApp) bool {
-	_, err := wordpressGetRelativeAbsPath(app)
-	if err != nil {
-		// Multiple abspath candidates is an issue, but is still a valid
-		// indicator that this is a WordPress app
-		if strings.Contains(err.Error(), "multiple") {
-			return true
-		}
-
-		return false
+// detectWordpressApp returns the files showing the app is of type wordpress:
+// wp-settings.php in the docroot, or else in its subdirectories. More than
+// one of those is an issue, but still a valid indicator of a WordPress app.
+func detectWordpressApp(app *DdevApp) []string {
+	if files := app.existingFiles(app.Docroot, "wp-settings.php"); len(files) > 0 {
+		return files
 	}
-
-	return true
+	return app.existingFiles(app.Docroot, "*/wp-settings.php")
 }
 
 // wordpressImportFilesAction defines the Wordpress workflow for importing project files.
@@ -0,0 +1,67 @@
+package ddevapp
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Values of xdebug_status in 'ddev describe' and 'ddev xdebug status'
+const (
+	XdebugStatusEnabled  = "enabled"
+	XdebugStatusDisabled = "disabled"
+)
+
+// XdebugStatus returns true if the xdebug extension is currently loaded in the
+// running web container.
+func (app *DdevApp) XdebugStatus() (bool, error) {
+	stdout, stderr, err := app.Exec(&ExecOpts{
+		Service: "web",
+		Cmd:     fmt.Sprintf("php -m | grep -qi '^xdebug$' && echo %s || echo %s", XdebugStatusEnabled, XdebugStatusDisabled),
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get xdebug status: %v, stderr=%s", err, stderr)
+	}
+	return strings.TrimSpace(stdout) == XdebugStatusEnabled, nil
+}
+
+// GetXdebugStatus returns XdebugStatusEnabled or XdebugStatusDisabled,
+// depending on whether the running web container has xdebug loaded. Unlike
+// xdebug_enabled, which is the configured value, this reflects changes made
+// with 'ddev xdebug'. It costs a docker exec, so it's not part of Describe().
+func (app *DdevApp) GetXdebugStatus() (string, error) {
+	enabled, err := app.XdebugStatus()
+	if err != nil {
+		return "", err
+	}
+	if enabled {
+		return XdebugStatusEnabled, nil
+	}
+	return XdebugStatusDisabled, nil
+}
+
+// SetXdebug enables or disables the xdebug extension in the running web
+// container, without a restart, by using phpenmod/phpdismod and reloading
+// php-fpm (or apache, with apache-cgi).
+// The change lasts until the web container is recreated, for example with
+// 'ddev restart', which uses xdebug_enabled from config.yaml again.
+func (app *DdevApp) SetXdebug(enable bool) error {
+	modCmd := "phpdismod"
+	if enable {
+		modCmd = "phpenmod"
+	}
+	// USR2 makes the php-fpm master process (the oldest one) gracefully reload.
+	reload := "(pkill -USR2 -o php-fpm || true)"
+	if app.GetWebserverType() == WebserverApacheCGI {
+		reload = "apache2ctl -k graceful"
+	}
+
+	_, stderr, err := app.Exec(&ExecOpts{
+		Service: "web",
+		User:    "root",
+		Cmd:     fmt.Sprintf("%s -v %s -s ALL xdebug && %s", modCmd, app.GetPhpVersion(), reload),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run %s xdebug: %v, stderr=%s", modCmd, err, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,276 @@
+package ddevdns
+
+// This package is a tiny DNS server that answers A (and AAAA) queries for
+// every name under a set of domains (the project TLDs) with a single IP
+// address, so ddev projects can be resolved without network access and
+// without hosts file edits. It intentionally implements only what's needed
+// for that, over UDP.
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+)
+
+// DefaultListenAddr is the address the resolver listens on unless told otherwise.
+// A port other than 53 is used so the resolver doesn't need root privileges and
+// doesn't conflict with a system resolver, and not 5353, which belongs to mDNS.
+const DefaultListenAddr = "127.0.0.1:5300"
+
+// DNS constants used by the resolver.
+const (
+	headerLen = 12
+
+	typeA    = 1
+	typeAAAA = 28
+	typeANY  = 255
+	classIN  = 1
+
+	rcodeSuccess        = 0
+	rcodeFormatError    = 1
+	rcodeNotImplemented = 4
+	rcodeRefused        = 5
+
+	// answerTTL is short so changes in the project list are picked up quickly.
+	answerTTL = 60
+)
+
+// Server answers queries for names under Domains with IP.
+type Server struct {
+	IP      net.IP
+	domains []string
+	conn    net.PacketConn
+	mu      sync.RWMutex
+}
+
+// NewServer returns a server that resolves all names under the provided domains to ip.
+func NewServer(ip string, domains []string) (*Server, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("%q is not a valid IP address", ip)
+	}
+	s := &Server{IP: parsed}
+	s.SetDomains(domains)
+	return s, nil
+}
+
+// SetDomains replaces the list of domains the server is authoritative for.
+func (s *Server) SetDomains(domains []string) {
+	var normalized []string
+	for _, d := range domains {
+		d = strings.ToLower(strings.Trim(strings.TrimSpace(d), "."))
+		if d != "" {
+			normalized = append(normalized, d)
+		}
+	}
+	s.mu.Lock()
+	s.domains = normalized
+	s.mu.Unlock()
+}
+
+// Domains returns the list of domains the server is authoritative for.
+func (s *Server) Domains() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string{}, s.domains...)
+}
+
+// Listen opens the UDP socket at addr. Use Addr() to find out the actual
+// address when listening on port 0.
+func (s *Server) Listen(addr string) error {
+	conn, err := net.ListenPacket("udp", addr)
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	return nil
+}
+
+// Addr returns the address the server is listening on.
+func (s *Server) Addr() net.Addr {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.LocalAddr()
+}
+
+// Serve answers queries until Close() is called. Listen() must be called first.
+func (s *Server) Serve() error {
+	if s.conn == nil {
+		return fmt.Errorf("dns server is not listening")
+	}
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			// A closed socket is the normal way to stop serving.
+			if strings.Contains(err.Error(), "use of closed network connection") {
+				return nil
+			}
+			return err
+		}
+		response := s.HandleQuery(buf[:n])
+		if response != nil {
+			_, _ = s.conn.WriteTo(response, addr)
+		}
+	}
+}
+
+// Close stops the server.
+func (s *Server) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	return s.conn.Close()
+}
+
+// isAuthoritative returns true if name is one of the server's domains or below one.
+func (s *Server) isAuthoritative(name string) bool {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	for _, d := range s.Domains() {
+		if name == d || strings.HasSuffix(name, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleQuery builds the response for a single DNS query packet.
+// It returns nil if the packet is too mangled to answer at all.
+func (s *Server) HandleQuery(query []byte) []byte {
+	if len(query) < headerLen {
+		return nil
+	}
+	flags := binary.BigEndian.Uint16(query[2:4])
+	// Ignore anything that is itself a response.
+	if flags&0x8000 != 0 {
+		return nil
+	}
+	opcode := (flags >> 11) & 0xF
+	if opcode != 0 {
+		return buildResponse(query[:headerLen], nil, rcodeNotImplemented, nil)
+	}
+	if binary.BigEndian.Uint16(query[4:6]) != 1 {
+		return buildResponse(query[:headerLen], nil, rcodeFormatError, nil)
+	}
+
+	name, end, err := readName(query, headerLen)
+	if err != nil || end+4 > len(query) {
+		return buildResponse(query[:headerLen], nil, rcodeFormatError, nil)
+	}
+	question := query[headerLen : end+4]
+	qtype := binary.BigEndian.Uint16(query[end : end+2])
+	qclass := binary.BigEndian.Uint16(query[end+2 : end+4])
+
+	if !s.isAuthoritative(name) {
+		return buildResponse(query[:headerLen], question, rcodeRefused, nil)
+	}
+
+	var answer []byte
+	ip4 := s.IP.To4()
+	switch {
+	case qclass != classIN:
+	case (qtype == typeA || qtype == typeANY) && ip4 != nil:
+		answer = buildAnswer(typeA, ip4)
+	case (qtype == typeAAAA || qtype == typeANY) && ip4 == nil:
+		answer = buildAnswer(typeAAAA, s.IP.To16())
+	}
+	// Any other type of query gets an empty, successful answer.
+	return buildResponse(query[:headerLen], question, rcodeSuccess, answer)
+}
+
+// readName decodes the (uncompressed) name starting at offset, returning the
+// name and the offset just past it.
+func readName(packet []byte, offset int) (string, int, error) {
+	var labels []string
+	for {
+		if offset >= len(packet) {
+			return "", 0, fmt.Errorf("name runs past end of packet")
+		}
+		length := int(packet[offset])
+		offset++
+		if length == 0 {
+			break
+		}
+		// Compression pointers and extended label types aren't valid in a question.
+		if length&0xC0 != 0 || offset+length > len(packet) {
+			return "", 0, fmt.Errorf("invalid label in name")
+		}
+		labels = append(labels, string(packet[offset:offset+length]))
+		offset += length
+	}
+	return strings.Join(labels, "."), offset, nil
+}
+
+// buildAnswer builds a resource record pointing at the name in the question.
+func buildAnswer(rrtype uint16, rdata []byte) []byte {
+	answer := make([]byte, 12, 12+len(rdata))
+	// 0xC00C is a compression pointer to the name right after the header.
+	binary.BigEndian.PutUint16(answer[0:2], 0xC00C)
+	binary.BigEndian.PutUint16(answer[2:4], rrtype)
+	binary.BigEndian.PutUint16(answer[4:6], classIN)
+	binary.BigEndian.PutUint32(answer[6:10], answerTTL)
+	binary.BigEndian.PutUint16(answer[10:12], uint16(len(rdata)))
+	return append(answer, rdata...)
+}
+
+// buildResponse builds a response packet from the query header, the question
+// section (if any) and an answer record (if any).
+func buildResponse(queryHeader []byte, question []byte, rcode uint16, answer []byte) []byte {
+	response := make([]byte, headerLen, headerLen+len(question)+len(answer))
+	copy(response[0:2], queryHeader[0:2])
+	queryFlags := binary.BigEndian.Uint16(queryHeader[2:4])
+	// QR and AA set, opcode and RD copied from the query.
+	flags := uint16(0x8000|0x0400) | (queryFlags & 0x7900) | rcode
+	binary.BigEndian.PutUint16(response[2:4], flags)
+	if question != nil {
+		binary.BigEndian.PutUint16(response[4:6], 1)
+	}
+	if answer != nil {
+		binary.BigEndian.PutUint16(response[6:8], 1)
+	}
+	response = append(response, question...)
+	return append(response, answer...)
+}
+
+// ResolverInstructions explains how to route lookups for the domains to a
+// resolver listening on listenAddr, for the provided operating system
+// (as in runtime.GOOS).
+func ResolverInstructions(listenAddr string, domains []string, goos string) string {
+	host, port, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		host, port = listenAddr, "53"
+	}
+
+	var b strings.Builder
+	switch goos {
+	case "linux":
+		var routed []string
+		for _, d := range domains {
+			routed = append(routed, "~"+d)
+		}
+		fmt.Fprintf(&b, "To send lookups for %s to the ddev resolver with systemd-resolved split DNS\n", strings.Join(domains, ", "))
+		fmt.Fprintf(&b, "(systemd 246 or later), create /etc/systemd/resolved.conf.d/ddev.conf containing:\n\n")
+		fmt.Fprintf(&b, "[Resolve]\nDNS=%s\nDomains=%s\n\n", listenAddr, strings.Join(routed, " "))
+		fmt.Fprintf(&b, "and then run 'sudo systemctl restart systemd-resolved'.\n")
+		fmt.Fprintf(&b, "To keep the resolver running, create ~/.config/systemd/user/ddev-dns.service containing:\n\n")
+		fmt.Fprintf(&b, "[Unit]\nDescription=ddev DNS resolver\n\n[Service]\nExecStart=ddev dns serve --listen=%s\nRestart=on-failure\n\n[Install]\nWantedBy=default.target\n\n", listenAddr)
+		fmt.Fprintf(&b, "and enable it with 'systemctl --user enable --now ddev-dns'.\n")
+	case "darwin":
+		example := "<domain>"
+		if len(domains) > 0 {
+			example = domains[0]
+		}
+		fmt.Fprintf(&b, "To send lookups to the ddev resolver, create one file per domain in /etc/resolver\n")
+		fmt.Fprintf(&b, "(for example 'sudo mkdir -p /etc/resolver' and then edit /etc/resolver/%s).\n", example)
+		fmt.Fprintf(&b, "Each file is named after the domain and contains:\n\n")
+		fmt.Fprintf(&b, "nameserver %s\nport %s\n\n", host, port)
+		fmt.Fprintf(&b, "Files needed: %s\n", "/etc/resolver/"+strings.Join(domains, ", /etc/resolver/"))
+	default:
+		fmt.Fprintf(&b, "Configure your system resolver or a local DNS server to forward lookups for\n")
+		fmt.Fprintf(&b, "%s to %s (port %s).\n", strings.Join(domains, ", "), host, port)
+	}
+	return b.String()
+}
@@ -0,0 +1,116 @@
+package ddevdns_test
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drud/ddev/pkg/ddevdns"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResolver starts the resolver on a random port and makes sure names under
+// the configured domains resolve and other names don't.
+func TestResolver(t *testing.T) {
+	assert := asrt.New(t)
+
+	server, err := ddevdns.NewServer("127.0.0.1", []string{"ddev.site", ".Example.Test."})
+	require.NoError(t, err)
+	err = server.Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	// nolint: errcheck
+	defer server.Close()
+	go func() {
+		_ = server.Serve()
+	}()
+
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			d := net.Dialer{}
+			return d.DialContext(ctx, "udp", server.Addr().String())
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, name := range []string{"mysite.ddev.site", "www.mysite.ddev.site", "ddev.site", "Other.EXAMPLE.test"} {
+		addrs, err := resolver.LookupHost(ctx, name)
+		assert.NoError(err, "failed to look up %s", name)
+		assert.Equal([]string{"127.0.0.1"}, addrs, "wrong answer for %s", name)
+	}
+
+	_, err = resolver.LookupHost(ctx, "mysite.example.com")
+	assert.Error(err)
+}
+
+// TestHandleQuery checks the raw responses for a few edge cases.
+func TestHandleQuery(t *testing.T) {
+	assert := asrt.New(t)
+
+	server, err := ddevdns.NewServer("192.168.99.100", []string{"ddev.site"})
+	require.NoError(t, err)
+
+	// Too short to be a query at all
+	assert.Nil(server.HandleQuery([]byte{1, 2, 3}))
+
+	// An A query gets the address back, with the query ID and question echoed.
+	response := server.HandleQuery(buildQuery(0x1234, "x.ddev.site", 1))
+	require.NotNil(t, response)
+	assert.Equal(uint16(0x1234), binary.BigEndian.Uint16(response[0:2]))
+	assert.Equal(uint16(0), binary.BigEndian.Uint16(response[2:4])&0xF, "rcode")
+	assert.Equal(uint16(1), binary.BigEndian.Uint16(response[6:8]), "answer count")
+	assert.Equal([]byte{192, 168, 99, 100}, response[len(response)-4:])
+
+	// An AAAA query for an IPv4 address is answered successfully, but empty.
+	response = server.HandleQuery(buildQuery(1, "x.ddev.site", 28))
+	require.NotNil(t, response)
+	assert.Equal(uint16(0), binary.BigEndian.Uint16(response[2:4])&0xF, "rcode")
+	assert.Equal(uint16(0), binary.BigEndian.Uint16(response[6:8]), "answer count")
+
+	// Names outside our domains are refused.
+	response = server.HandleQuery(buildQuery(1, "notddev.site", 1))
+	require.NotNil(t, response)
+	assert.Equal(uint16(5), binary.BigEndian.Uint16(response[2:4])&0xF, "rcode")
+
+	// A truncated question is a format error.
+	query := buildQuery(1, "x.ddev.site", 1)
+	response = server.HandleQuery(query[:len(query)-3])
+	require.NotNil(t, response)
+	assert.Equal(uint16(1), binary.BigEndian.Uint16(response[2:4])&0xF, "rcode")
+
+	_, err = ddevdns.NewServer("not-an-ip", nil)
+	assert.Error(err)
+}
+
+// TestResolverInstructions makes sure the instructions mention the configuration
+// each platform needs.
+func TestResolverInstructions(t *testing.T) {
+	assert := asrt.New(t)
+
+	linux := ddevdns.ResolverInstructions("127.0.0.1:5300", []string{"ddev.site", "example.test"}, "linux")
+	assert.Contains(linux, "DNS=127.0.0.1:5300")
+	assert.Contains(linux, "Domains=~ddev.site ~example.test")
+
+	darwin := ddevdns.ResolverInstructions("127.0.0.1:5300", []string{"ddev.site", "example.test"}, "darwin")
+	assert.Contains(darwin, "nameserver 127.0.0.1\nport 5300")
+	assert.Contains(darwin, "/etc/resolver/example.test")
+}
+
+// buildQuery builds a DNS query packet with one question.
+func buildQuery(id uint16, name string, qtype uint16) []byte {
+	query := make([]byte, 12)
+	binary.BigEndian.PutUint16(query[0:2], id)
+	binary.BigEndian.PutUint16(query[2:4], 0x0100)
+	binary.BigEndian.PutUint16(query[4:6], 1)
+	for _, label := range strings.Split(name, ".") {
+		query = append(query, byte(len(label)))
+		query = append(query, label...)
+	}
+	query = append(query, 0, byte(qtype>>8), byte(qtype), 0, 1)
+	return query
+}
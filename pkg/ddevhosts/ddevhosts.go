@@ -1,18 +1,44 @@
 package ddevhosts
 
-// This package is a simple wrapper on goodhosts.
-// Its only current purpose is to provide GetIPPosition as an
-// exported function.
+// This package is a wrapper on goodhosts.
+// It provides GetIPPosition as an exported function, and manages
+// a marker-delimited block of the hosts file that belongs to ddev,
+// so all ddev entries can be listed, rewritten and cleaned up at once.
 
 import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
 	"github.com/lextoumbourou/goodhosts"
 )
 
+// HostsFileEnv is the environment variable that can be used to point ddev
+// at a hosts file other than the system one, mostly for testing.
+const HostsFileEnv = "DDEV_HOSTS_FILE"
+
+// ManagedBlockBegin and ManagedBlockEnd delimit the section of the hosts file
+// that ddev owns. Everything between them may be rewritten by ddev at any time.
+const (
+	ManagedBlockBegin = "# ddev managed hosts begin - do not edit this section by hand"
+	ManagedBlockEnd   = "# ddev managed hosts end"
+)
+
 // DdevHosts uses composition to absorb all exported functions of goodhosts
 type DdevHosts struct {
 	goodhosts.Hosts // provides all exported functions from goodhosts
 }
 
+// Entry is a single hostname/IP pair in the ddev-managed block.
+type Entry struct {
+	IP       string `json:"ip"`
+	Hostname string `json:"hostname"`
+}
+
 // GetIPPosition is the same as the unexported getIpPosition,
 // providing the position of the line in the hosts file that
 // supports the IP address we're looking for.
@@ -30,12 +56,211 @@ func (h DdevHosts) GetIPPosition(ip string) int {
 	return -1
 }
 
-// New() is a simple wrapper on goodhosts.NewHosts()
+// GetHostsFilePath returns the path of the hosts file ddev manages,
+// which is the system hosts file unless overridden with $DDEV_HOSTS_FILE.
+func GetHostsFilePath() string {
+	if path := os.Getenv(HostsFileEnv); path != "" {
+		return path
+	}
+	if runtime.GOOS == "windows" {
+		return os.ExpandEnv(filepath.FromSlash("${SystemRoot}/System32/drivers/etc/hosts"))
+	}
+	return "/etc/hosts"
+}
+
+// New() loads the hosts file returned by GetHostsFilePath()
 func New() (*DdevHosts, error) {
-	h, err := goodhosts.NewHosts()
+	return NewWithPath(GetHostsFilePath())
+}
+
+// NewWithPath loads the hosts file at the provided path.
+func NewWithPath(path string) (*DdevHosts, error) {
+	h := goodhosts.Hosts{Path: path}
+	err := h.Load()
 	if err != nil {
 		return nil, err
 	}
 
 	return &DdevHosts{h}, nil
 }
+
+// managedBlockPosition returns the line indexes of the begin and end markers
+// of the ddev-managed block, or -1, -1 if there is no complete block.
+func (h DdevHosts) managedBlockPosition() (int, int) {
+	begin := -1
+	for i, line := range h.Lines {
+		trimmed := strings.TrimSpace(line.Raw)
+		if trimmed == ManagedBlockBegin && begin == -1 {
+			begin = i
+		}
+		if trimmed == ManagedBlockEnd && begin != -1 {
+			return begin, i
+		}
+	}
+	return -1, -1
+}
+
+// ManagedEntries returns the entries in the ddev-managed block, sorted by hostname.
+func (h DdevHosts) ManagedEntries() []Entry {
+	entries := []Entry{}
+	begin, end := h.managedBlockPosition()
+	if begin == -1 {
+		return entries
+	}
+	for _, line := range h.Lines[begin+1 : end] {
+		if line.IsComment() || line.Err != nil || line.IP == "" {
+			continue
+		}
+		for _, hostname := range line.Hosts {
+			entries = append(entries, Entry{IP: line.IP, Hostname: hostname})
+		}
+	}
+	sortEntries(entries)
+	return entries
+}
+
+// HasManaged returns true if the ip/hostname pair is in the ddev-managed block.
+func (h DdevHosts) HasManaged(ip string, hostname string) bool {
+	for _, entry := range h.ManagedEntries() {
+		if entry.IP == ip && entry.Hostname == hostname {
+			return true
+		}
+	}
+	return false
+}
+
+// UnmanagedHostnames returns the hostnames for ip found outside of the
+// ddev-managed block, for example those added by older versions of ddev.
+func (h DdevHosts) UnmanagedHostnames(ip string) []string {
+	hostnames := []string{}
+	begin, end := h.managedBlockPosition()
+	for i, line := range h.Lines {
+		if begin != -1 && i >= begin && i <= end {
+			continue
+		}
+		if line.IsComment() || line.Err != nil || line.IP != ip {
+			continue
+		}
+		hostnames = append(hostnames, line.Hosts...)
+	}
+	return hostnames
+}
+
+// SetManagedEntries replaces the contents of the ddev-managed block with entries,
+// writing one line per hostname. Any of the hostnames that also appear outside
+// of the block for the same IP are removed there, so they're only listed once.
+// An empty list of entries removes the block entirely.
+// The changes are only made in memory; use WriteAtomic() to save them.
+func (h *DdevHosts) SetManagedEntries(entries []Entry) error {
+	entries = dedupeEntries(entries)
+
+	var unmanaged []goodhosts.HostsLine
+	begin, end := h.managedBlockPosition()
+	for i, line := range h.Lines {
+		if begin != -1 && i >= begin && i <= end {
+			continue
+		}
+		unmanaged = append(unmanaged, line)
+	}
+	h.Lines = unmanaged
+
+	for _, entry := range entries {
+		if err := h.Remove(entry.IP, entry.Hostname); err != nil {
+			return err
+		}
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// Keep the block visually separated from whatever comes before it.
+	if len(h.Lines) > 0 && strings.TrimSpace(h.Lines[len(h.Lines)-1].Raw) != "" {
+		h.Lines = append(h.Lines, goodhosts.NewHostsLine(""))
+	}
+	h.Lines = append(h.Lines, goodhosts.NewHostsLine(ManagedBlockBegin))
+	for _, entry := range entries {
+		h.Lines = append(h.Lines, goodhosts.NewHostsLine(entry.IP+" "+entry.Hostname))
+	}
+	h.Lines = append(h.Lines, goodhosts.NewHostsLine(ManagedBlockEnd))
+
+	return nil
+}
+
+// WriteAtomic writes the hosts file by writing a temporary file next to it
+// and renaming it into place, so readers never see a partially written file.
+// If the hosts file is a symlink its target is replaced, keeping the owner
+// and mode of the original. If the rename isn't possible (for example when
+// the hosts file is a bind mount) the file is written in place instead.
+func (h *DdevHosts) WriteAtomic() error {
+	eol := "\n"
+	if runtime.GOOS == "windows" {
+		eol = "\r\n"
+	}
+	var content strings.Builder
+	for _, line := range h.Lines {
+		content.WriteString(line.Raw + eol)
+	}
+
+	path := h.Path
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	mode := os.FileMode(0644)
+	fi, statErr := os.Stat(path)
+	if statErr == nil {
+		mode = fi.Mode()
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(path), ".ddev-hosts")
+	if err == nil {
+		tmpName := tmpFile.Name()
+		_, err = tmpFile.WriteString(content.String())
+		if closeErr := tmpFile.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Chmod(tmpName, mode)
+		}
+		if err == nil && statErr == nil {
+			err = copyOwner(tmpName, fi)
+		}
+		if err == nil {
+			err = os.Rename(tmpName, path)
+		}
+		if err != nil {
+			_ = os.Remove(tmpName)
+		}
+	}
+	if err != nil {
+		err = ioutil.WriteFile(path, []byte(content.String()), mode)
+		if err != nil {
+			return fmt.Errorf("failed to write hosts file %s: %v", h.Path, err)
+		}
+	}
+
+	return h.Load()
+}
+
+// dedupeEntries returns entries sorted by hostname with duplicates removed.
+func dedupeEntries(entries []Entry) []Entry {
+	seen := make(map[Entry]bool)
+	result := []Entry{}
+	for _, entry := range entries {
+		if seen[entry] {
+			continue
+		}
+		seen[entry] = true
+		result = append(result, entry)
+	}
+	sortEntries(result)
+	return result
+}
+
+func sortEntries(entries []Entry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Hostname == entries[j].Hostname {
+			return entries[i].IP < entries[j].IP
+		}
+		return entries[i].Hostname < entries[j].Hostname
+	})
+}
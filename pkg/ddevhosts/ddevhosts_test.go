@@ -0,0 +1,152 @@
+package ddevhosts_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/drud/ddev/pkg/ddevhosts"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHostsContent = `127.0.0.1 localhost
+::1 localhost
+# A comment that must be preserved
+127.0.0.1 legacy.ddev.site other.example.com
+`
+
+// writeTestHosts writes a hosts file with testHostsContent into a temporary
+// directory and returns its path.
+func writeTestHosts(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "ddevhosts")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "hosts")
+	err = ioutil.WriteFile(path, []byte(testHostsContent), 0644)
+	require.NoError(t, err)
+	return path
+}
+
+// TestManagedEntries makes sure entries can be added to the ddev-managed block,
+// listed, and removed, without disturbing the rest of the hosts file.
+func TestManagedEntries(t *testing.T) {
+	assert := asrt.New(t)
+
+	path := writeTestHosts(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	hosts, err := ddevhosts.NewWithPath(path)
+	require.NoError(t, err)
+	assert.Empty(hosts.ManagedEntries())
+
+	entries := []ddevhosts.Entry{
+		{IP: "127.0.0.1", Hostname: "two.ddev.site"},
+		{IP: "127.0.0.1", Hostname: "one.ddev.site"},
+		{IP: "127.0.0.1", Hostname: "one.ddev.site"},
+		{IP: "127.0.0.1", Hostname: "legacy.ddev.site"},
+	}
+	err = hosts.SetManagedEntries(entries)
+	require.NoError(t, err)
+	err = hosts.WriteAtomic()
+	require.NoError(t, err)
+
+	// Reread from disk to make sure what was written is what we get back.
+	hosts, err = ddevhosts.NewWithPath(path)
+	require.NoError(t, err)
+	assert.Equal([]ddevhosts.Entry{
+		{IP: "127.0.0.1", Hostname: "legacy.ddev.site"},
+		{IP: "127.0.0.1", Hostname: "one.ddev.site"},
+		{IP: "127.0.0.1", Hostname: "two.ddev.site"},
+	}, hosts.ManagedEntries())
+	assert.True(hosts.HasManaged("127.0.0.1", "one.ddev.site"))
+	assert.False(hosts.HasManaged("127.0.0.2", "one.ddev.site"))
+
+	// The legacy entry was moved into the block, the unrelated one is untouched.
+	assert.Equal([]string{"localhost", "other.example.com"}, hosts.UnmanagedHostnames("127.0.0.1"))
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(string(content), "# A comment that must be preserved")
+	assert.Equal(1, strings.Count(string(content), ddevhosts.ManagedBlockBegin))
+	assert.Equal(1, strings.Count(string(content), ddevhosts.ManagedBlockEnd))
+
+	// Rewriting the block replaces it instead of adding another one.
+	err = hosts.SetManagedEntries([]ddevhosts.Entry{{IP: "127.0.0.1", Hostname: "two.ddev.site"}})
+	require.NoError(t, err)
+	err = hosts.WriteAtomic()
+	require.NoError(t, err)
+	assert.Equal([]ddevhosts.Entry{{IP: "127.0.0.1", Hostname: "two.ddev.site"}}, hosts.ManagedEntries())
+	content, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(1, strings.Count(string(content), ddevhosts.ManagedBlockBegin))
+	assert.NotContains(string(content), "one.ddev.site")
+
+	// An empty set of entries removes the block completely.
+	err = hosts.SetManagedEntries(nil)
+	require.NoError(t, err)
+	err = hosts.WriteAtomic()
+	require.NoError(t, err)
+	content, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(string(content), ddevhosts.ManagedBlockBegin)
+	assert.NotContains(string(content), ddevhosts.ManagedBlockEnd)
+	assert.Contains(string(content), "127.0.0.1 localhost")
+}
+
+// TestWriteAtomicSymlink makes sure writing a symlinked hosts file updates
+// its target, keeping the symlink and the target's mode.
+func TestWriteAtomicSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping on Windows, where creating symlinks needs privileges")
+	}
+	assert := asrt.New(t)
+
+	target := writeTestHosts(t)
+	defer os.RemoveAll(filepath.Dir(target))
+	err := os.Chmod(target, 0640)
+	require.NoError(t, err)
+	link := filepath.Join(filepath.Dir(target), "hosts-link")
+	err = os.Symlink(target, link)
+	require.NoError(t, err)
+
+	hosts, err := ddevhosts.NewWithPath(link)
+	require.NoError(t, err)
+	err = hosts.SetManagedEntries([]ddevhosts.Entry{{IP: "127.0.0.1", Hostname: "one.ddev.site"}})
+	require.NoError(t, err)
+	err = hosts.WriteAtomic()
+	require.NoError(t, err)
+
+	fi, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.True(fi.Mode()&os.ModeSymlink != 0)
+	fi, err = os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(os.FileMode(0640), fi.Mode().Perm())
+	content, err := ioutil.ReadFile(target)
+	require.NoError(t, err)
+	assert.Contains(string(content), "one.ddev.site")
+}
+
+// TestGetHostsFilePath makes sure $DDEV_HOSTS_FILE overrides the system hosts file.
+func TestGetHostsFilePath(t *testing.T) {
+	assert := asrt.New(t)
+
+	path := writeTestHosts(t)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	orig := os.Getenv(ddevhosts.HostsFileEnv)
+	// nolint: errcheck
+	defer os.Setenv(ddevhosts.HostsFileEnv, orig)
+
+	err := os.Setenv(ddevhosts.HostsFileEnv, path)
+	require.NoError(t, err)
+	assert.Equal(path, ddevhosts.GetHostsFilePath())
+
+	hosts, err := ddevhosts.New()
+	require.NoError(t, err)
+	assert.Equal(path, hosts.Path)
+	assert.True(hosts.Has("127.0.0.1", "legacy.ddev.site"))
+}
@@ -0,0 +1,17 @@
+// +build !windows
+
+package ddevhosts
+
+import (
+	"os"
+	"syscall"
+)
+
+// copyOwner gives path the owner and group of the file described by fi.
+func copyOwner(path string, fi os.FileInfo) error {
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	return os.Chown(path, int(stat.Uid), int(stat.Gid))
+}
@@ -0,0 +1,9 @@
+package ddevhosts
+
+import "os"
+
+// copyOwner is a no-op on Windows, where a new file in the hosts file's
+// directory gets its permissions from the directory.
+func copyOwner(path string, fi os.FileInfo) error {
+	return nil
+}
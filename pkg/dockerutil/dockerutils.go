@@ -272,6 +272,12 @@ func GetContainerHealth(container *docker.APIContainers) (string, string) {
 // ComposeWithStreams executes a docker-compose command but allows the caller to specify
 // stdin/stdout/stderr
 func ComposeWithStreams(composeFiles []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, action ...string) error {
+	return ComposeWithStreamsEnv(nil, composeFiles, stdin, stdout, stderr, action...)
+}
+
+// ComposeWithStreamsEnv is ComposeWithStreams with env as the environment of
+// docker-compose; a nil env means the environment of ddev itself.
+func ComposeWithStreamsEnv(env []string, composeFiles []string, stdin io.Reader, stdout io.Writer, stderr io.Writer, action ...string) error {
 	var arg []string
 
 	for _, file := range composeFiles {
@@ -282,6 +288,7 @@ func ComposeWithStreams(composeFiles []string, stdin io.Reader, stdout io.Writer
 	arg = append(arg, action...)
 
 	proc := exec.Command("docker-compose", arg...)
+	proc.Env = env
 	proc.Stdout = stdout
 	proc.Stdin = stdin
 	proc.Stderr = stderr
@@ -293,6 +300,12 @@ func ComposeWithStreams(composeFiles []string, stdin io.Reader, stdout io.Writer
 // ComposeCmd executes docker-compose commands via shell.
 // returns stdout, stderr, error/nil
 func ComposeCmd(composeFiles []string, action ...string) (string, string, error) {
+	return ComposeCmdEnv(nil, composeFiles, action...)
+}
+
+// ComposeCmdEnv is ComposeCmd with env as the environment of docker-compose;
+// a nil env means the environment of ddev itself.
+func ComposeCmdEnv(env []string, composeFiles []string, action ...string) (string, string, error) {
 	var arg []string
 	var stdout bytes.Buffer
 	var stderr string
@@ -305,6 +318,7 @@ func ComposeCmd(composeFiles []string, action ...string) (string, string, error)
 	arg = append(arg, action...)
 
 	proc := exec.Command("docker-compose", arg...)
+	proc.Env = env
 	proc.Stdout = &stdout
 	proc.Stdin = os.Stdin
 
@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 // DdevGlobalConfigName is the name of the global config file.
@@ -22,6 +23,10 @@ const DdevGlobalConfigName = "global_config.yaml"
 var (
 	// DdevGlobalConfig is the currently active global configuration struct
 	DdevGlobalConfig GlobalConfig
+
+	// configLock guards DdevGlobalConfig and the global config file when
+	// several projects are handled at the same time.
+	configLock sync.Mutex
 )
 
 func init() {
@@ -41,6 +46,7 @@ type GlobalConfig struct {
 	LastUsedVersion      string                  `yaml:"last_used_version"`
 	ProjectList          map[string]*ProjectInfo `yaml:"project_info"`
 	DeveloperMode        bool                    `yaml:"developer_mode,omitempty"`
+	RouterPortFallback   bool                    `yaml:"router_port_fallback,omitempty"`
 }
 
 // GetGlobalConfigPath() gets the path to global config file
@@ -59,6 +65,9 @@ func ValidateGlobalConfig() error {
 
 // ReadGlobalConfig() reads the global config file into DdevGlobalConfig
 func ReadGlobalConfig() error {
+	configLock.Lock()
+	defer configLock.Unlock()
+
 	globalConfigFile := GetGlobalConfigPath()
 	// This is added just so we can see it in global; not checked.
 	DdevGlobalConfig.APIVersion = version.DdevVersion
@@ -72,7 +81,7 @@ func ReadGlobalConfig() error {
 			return nil
 		}
 		if os.IsNotExist(err) {
-			err := WriteGlobalConfig(GlobalConfig{})
+			err := writeGlobalConfig(GlobalConfig{})
 			if err != nil {
 				return err
 			}
@@ -101,7 +110,24 @@ func ReadGlobalConfig() error {
 
 // WriteGlobalConfig writes the global config into ~/.ddev.
 func WriteGlobalConfig(config GlobalConfig) error {
+	configLock.Lock()
+	defer configLock.Unlock()
+
+	return writeGlobalConfig(config)
+}
+
+// UpdateGlobalConfig applies update to DdevGlobalConfig and writes the
+// result, holding configLock so concurrent updates don't get lost.
+func UpdateGlobalConfig(update func(config *GlobalConfig)) error {
+	configLock.Lock()
+	defer configLock.Unlock()
+
+	update(&DdevGlobalConfig)
+	return writeGlobalConfig(DdevGlobalConfig)
+}
 
+// writeGlobalConfig writes the global config; the caller holds configLock.
+func writeGlobalConfig(config GlobalConfig) error {
 	err := ValidateGlobalConfig()
 	if err != nil {
 		return err
@@ -112,7 +138,7 @@ func WriteGlobalConfig(config GlobalConfig) error {
 	}
 
 	// Append current image information
-	instructions := "\n# You can turn off usage of the dba (phpmyadmin) container and/or \n# ddev-ssh-agent containers with\n# omit_containers[\"dba\", \"ddev-ssh-agent\"]\n\n# and you can opt in or out of sending instrumentation the ddev developers with \n# instrumentation_opt_in: true # or false\n"
+	instructions := "\n# You can turn off usage of the dba (phpmyadmin) container and/or \n# ddev-ssh-agent containers with\n# omit_containers[\"dba\", \"ddev-ssh-agent\"]\n\n# Omitting \"ddev-router\" runs all projects without the router, using only\n# the web container's directly published ports.\n\n# With router_port_fallback: true, ddev picks free router ports for a project\n# when its router_http_port or router_https_port is already in use.\n\n# and you can opt in or out of sending instrumentation the ddev developers with \n# instrumentation_opt_in: true # or false\n"
 	cfgbytes = append(cfgbytes, instructions...)
 
 	err = ioutil.WriteFile(GetGlobalConfigPath(), cfgbytes, 0644)
@@ -176,6 +202,9 @@ func GetValidOmitContainers() []string {
 // HostPortIsAllocated returns the project name that has allocated
 // the port, or empty string.
 func HostPostIsAllocated(port string) string {
+	configLock.Lock()
+	defer configLock.Unlock()
+
 	for project, item := range DdevGlobalConfig.ProjectList {
 		if nodeps.ArrayContainsString(item.UsedHostPorts, port) {
 			return project
@@ -234,18 +263,24 @@ func GetFreePort(localIPAddr string) (string, error) {
 
 // ReservePorts() adds the ProjectInfo if necessary and assigns the reserved ports
 func ReservePorts(projectName string, ports []string) error {
+	configLock.Lock()
+	defer configLock.Unlock()
+
 	// If the project doesn't exist, add it.
 	_, ok := DdevGlobalConfig.ProjectList[projectName]
 	if !ok {
 		DdevGlobalConfig.ProjectList[projectName] = &ProjectInfo{}
 	}
 	DdevGlobalConfig.ProjectList[projectName].UsedHostPorts = ports
-	err := WriteGlobalConfig(DdevGlobalConfig)
+	err := writeGlobalConfig(DdevGlobalConfig)
 	return err
 }
 
 // SetProjectAppRoot() sets the approot in the ProjectInfo of global config
 func SetProjectAppRoot(projectName string, appRoot string) error {
+	configLock.Lock()
+	defer configLock.Unlock()
+
 	// If the project doesn't exist, add it.
 	_, ok := DdevGlobalConfig.ProjectList[projectName]
 	if !ok {
@@ -259,13 +294,16 @@ func SetProjectAppRoot(projectName string, appRoot string) error {
 		return fmt.Errorf("project %s project root is already set to %s, refusing to change it to %s; you can `ddev rm --unlist` and start again if the listed project root is in error", projectName, DdevGlobalConfig.ProjectList[projectName].AppRoot, appRoot)
 	}
 	DdevGlobalConfig.ProjectList[projectName].AppRoot = appRoot
-	err := WriteGlobalConfig(DdevGlobalConfig)
+	err := writeGlobalConfig(DdevGlobalConfig)
 	return err
 }
 
 // GetProject returns a project given name provided,
 // or nil if not found.
 func GetProject(projectName string) *ProjectInfo {
+	configLock.Lock()
+	defer configLock.Unlock()
+
 	project, ok := DdevGlobalConfig.ProjectList[projectName]
 	if !ok {
 		return nil
@@ -275,10 +313,13 @@ func GetProject(projectName string) *ProjectInfo {
 
 // RemoveProjectInfo() removes the ProjectInfo line for a project
 func RemoveProjectInfo(projectName string) error {
+	configLock.Lock()
+	defer configLock.Unlock()
+
 	_, ok := DdevGlobalConfig.ProjectList[projectName]
 	if ok {
 		delete(DdevGlobalConfig.ProjectList, projectName)
-		err := WriteGlobalConfig(DdevGlobalConfig)
+		err := writeGlobalConfig(DdevGlobalConfig)
 		if err != nil {
 			return err
 		}
@@ -286,7 +327,16 @@ func RemoveProjectInfo(projectName string) error {
 	return nil
 }
 
-// GetGlobalProjectList() returns the global project list map
+// GetGlobalProjectList() returns a copy of the global project list map,
+// so it can be used while projects are being added or removed.
 func GetGlobalProjectList() map[string]*ProjectInfo {
-	return DdevGlobalConfig.ProjectList
+	configLock.Lock()
+	defer configLock.Unlock()
+
+	projectList := make(map[string]*ProjectInfo, len(DdevGlobalConfig.ProjectList))
+	for name, info := range DdevGlobalConfig.ProjectList {
+		infoCopy := *info
+		projectList[name] = &infoCopy
+	}
+	return projectList
 }
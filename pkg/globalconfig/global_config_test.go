@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"os"
 	"strconv"
+	"sync"
 	"testing"
 )
 
@@ -107,3 +108,56 @@ func TestSetProjectAppRoot(t *testing.T) {
 	project = globalconfig.GetProject(t.Name())
 	assert.Equal(tmpDir, project.AppRoot)
 }
+
+// TestConcurrentProjectInfo makes sure projects can be added to and removed
+// from the global config at the same time, as parallel starts and stops do.
+func TestConcurrentProjectInfo(t *testing.T) {
+	assert := asrt.New(t)
+
+	tmpDir := testcommon.CreateTmpDir(t.Name())
+	// nolint: errcheck
+	defer os.RemoveAll(tmpDir)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 40)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			errs <- globalconfig.SetProjectAppRoot(name, tmpDir)
+			errs <- globalconfig.ReservePorts(name, []string{})
+			_ = globalconfig.GetGlobalProjectList()
+		}(t.Name() + strconv.Itoa(i))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(err)
+	}
+
+	for i := 0; i < 20; i++ {
+		name := t.Name() + strconv.Itoa(i)
+		if project := globalconfig.GetProject(name); assert.NotNil(project) {
+			assert.Equal(tmpDir, project.AppRoot)
+		}
+	}
+
+	errs = make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			errs <- globalconfig.UpdateGlobalConfig(func(config *globalconfig.GlobalConfig) {
+				delete(config.ProjectList, name)
+			})
+		}(t.Name() + strconv.Itoa(i))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(err)
+	}
+	for name := range globalconfig.GetGlobalProjectList() {
+		assert.NotContains(name, t.Name())
+	}
+}
@@ -6,11 +6,13 @@ import "os"
 const (
 	DdevSSHAgentContainer = "ddev-ssh-agent"
 	DBAContainer          = "dba"
+	DdevRouterContainer   = "ddev-router"
 )
 
 var ValidOmitContainers = map[string]bool{
 	DdevSSHAgentContainer: true,
 	DBAContainer:          true,
+	DdevRouterContainer:   true,
 }
 
 var DdevNoSentry = os.Getenv("DDEV_NO_SENTRY") == "true"
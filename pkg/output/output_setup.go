@@ -45,6 +45,7 @@ func LogSetUp() {
 		UserOut.Formatter = UserOutFormatter
 	} else {
 		UserOut.Formatter = &JSONFormatter{}
+		setUpResults()
 	}
 
 	UserOutFormatter.DisableTimestamp = true
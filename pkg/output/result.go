@@ -0,0 +1,148 @@
+package output
+
+import (
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ResultSchemaVersion is the version of the Result schema. It only changes
+// when fields are removed or change meaning; new fields may be added.
+const ResultSchemaVersion = 1
+
+// Result statuses
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// ResultMessage is a message a command printed before its Result.
+type ResultMessage struct {
+	Level   string `json:"level"`
+	Message string `json:"msg"`
+}
+
+// Result is the summary of a command, printed as the last line of its
+// output in JSON mode, so scripts and editor plugins don't have to parse the
+// text output. Its fields are added to the usual JSON log line fields, with
+// the command's results also in "raw" for compatibility.
+type Result struct {
+	SchemaVersion int             `json:"schema_version"`
+	Command       string          `json:"command"`
+	Status        string          `json:"status"`
+	Messages      []ResultMessage `json:"messages"`
+	Results       interface{}     `json:"results"`
+	Errors        []string        `json:"errors"`
+}
+
+var (
+	resultLock sync.Mutex
+	// result is the Result of the running command, nil if it has none.
+	result *Result
+	// resultSetUp makes sure the result hook is only added once.
+	resultSetUp sync.Once
+)
+
+// StartResult starts collecting the messages and errors of command for its
+// Result, which EmitResult prints; if the command fails with a fatal
+// error, the Result is printed with what's been collected before exiting.
+func StartResult(command string) {
+	resultLock.Lock()
+	defer resultLock.Unlock()
+	result = &Result{
+		SchemaVersion: ResultSchemaVersion,
+		Command:       command,
+		Status:        ResultSuccess,
+		Messages:      []ResultMessage{},
+		Errors:        []string{},
+	}
+}
+
+// SetResults sets the structured results of the running command, for when
+// it fails before it gets to EmitResult.
+func SetResults(results interface{}) {
+	resultLock.Lock()
+	defer resultLock.Unlock()
+	if result != nil {
+		result.Results = results
+	}
+}
+
+// EmitResult prints msg, with the Result of the running command and its
+// results in JSON mode. In text mode only msg is printed, if it's not empty.
+func EmitResult(msg string, results interface{}) {
+	if !JSONOutput {
+		if msg != "" {
+			UserOut.Print(msg)
+		}
+		return
+	}
+	SetResults(results)
+	emitResult(msg)
+}
+
+// emitResult prints the Result of the running command, if it has one and
+// it hasn't been printed yet.
+func emitResult(msg string) {
+	resultLock.Lock()
+	r := result
+	result = nil
+	resultLock.Unlock()
+	if r == nil {
+		return
+	}
+
+	if len(r.Errors) > 0 {
+		r.Status = ResultError
+	}
+	if msg == "" {
+		msg = r.Command + " " + r.Status
+	}
+	entry := UserOut.WithFields(log.Fields{
+		"schema_version": r.SchemaVersion,
+		"command":        r.Command,
+		"status":         r.Status,
+		"messages":       r.Messages,
+		"results":        r.Results,
+		"errors":         r.Errors,
+		"raw":            r.Results,
+	})
+	if r.Status == ResultError {
+		entry.Error(msg)
+	} else {
+		entry.Print(msg)
+	}
+}
+
+// resultHook collects the messages and errors of the running command.
+type resultHook struct{}
+
+// Levels returns all levels, since every message goes in the Result.
+func (resultHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+// Fire adds the message to the Result of the running command, if any.
+func (resultHook) Fire(entry *log.Entry) error {
+	resultLock.Lock()
+	defer resultLock.Unlock()
+	if result == nil {
+		return nil
+	}
+	result.Messages = append(result.Messages, ResultMessage{Level: entry.Level.String(), Message: entry.Message})
+	if entry.Level <= log.ErrorLevel {
+		result.Errors = append(result.Errors, entry.Message)
+	}
+	return nil
+}
+
+// setUpResults makes UserOut collect the messages for Results, and print
+// the Result of a command that fails with a fatal error.
+func setUpResults() {
+	resultSetUp.Do(func() {
+		UserOut.AddHook(resultHook{})
+		log.RegisterExitHandler(func() {
+			emitResult("")
+		})
+	})
+}
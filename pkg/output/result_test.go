@@ -0,0 +1,81 @@
+package output_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/drud/ddev/pkg/output"
+	asrt "github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEmitResult makes sure the last line of a command's JSON output has the
+// Result schema, with the messages, errors and results of the command.
+func TestEmitResult(t *testing.T) {
+	assert := asrt.New(t)
+
+	origJSONOutput := output.JSONOutput
+	origOut := output.UserOut.Out
+	origFormatter := output.UserOut.Formatter
+	defer func() {
+		output.JSONOutput = origJSONOutput
+		output.UserOut.Out = origOut
+		output.UserOut.Formatter = origFormatter
+	}()
+	output.JSONOutput = true
+	output.LogSetUp()
+	var out bytes.Buffer
+	output.UserOut.Out = &out
+
+	output.StartResult("start")
+	output.UserOut.Print("Starting project...")
+	output.UserOut.Warn("Something to know")
+	output.EmitResult("", []map[string]interface{}{{"name": "project", "status": output.ResultSuccess}})
+
+	result := lastJSONLine(t, out.String())
+	for _, key := range []string{"schema_version", "command", "status", "messages", "results", "errors", "raw", "msg", "level", "time"} {
+		assert.Contains(result, key)
+	}
+	assert.EqualValues(output.ResultSchemaVersion, result["schema_version"])
+	assert.Equal("start", result["command"])
+	assert.Equal(output.ResultSuccess, result["status"])
+	assert.Equal("info", result["level"])
+	assert.Equal([]interface{}{
+		map[string]interface{}{"level": "info", "msg": "Starting project..."},
+		map[string]interface{}{"level": "warning", "msg": "Something to know"},
+	}, result["messages"])
+	assert.Equal([]interface{}{}, result["errors"])
+	assert.Equal([]interface{}{map[string]interface{}{"name": "project", "status": output.ResultSuccess}}, result["results"])
+	assert.Equal(result["results"], result["raw"])
+
+	// Errors make the status "error", and messages aren't carried over to the
+	// next command.
+	out.Reset()
+	output.StartResult("describe")
+	output.UserOut.Error("Failed to describe project")
+	output.EmitResult("", nil)
+
+	result = lastJSONLine(t, out.String())
+	assert.Equal("describe", result["command"])
+	assert.Equal(output.ResultError, result["status"])
+	assert.Equal("error", result["level"])
+	assert.Equal([]interface{}{"Failed to describe project"}, result["errors"])
+	assert.Len(result["messages"], 1)
+	assert.Nil(result["results"])
+
+	// Without a started Result there's nothing to emit.
+	out.Reset()
+	output.EmitResult("", nil)
+	assert.Empty(out.String())
+}
+
+// lastJSONLine decodes the last line of JSON output.
+func lastJSONLine(t *testing.T, out string) map[string]interface{} {
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	result := map[string]interface{}{}
+	err := json.Unmarshal([]byte(lines[len(lines)-1]), &result)
+	require.NoError(t, err, "failed to decode %s", lines[len(lines)-1])
+	return result
+}
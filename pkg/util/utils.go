@@ -142,6 +142,21 @@ func IsCommandAvailable(cmdName string) bool {
 	return false
 }
 
+// ShellQuote quotes s for safe use as a single argument in a bash command.
+func ShellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'"'"'`, -1) + "'"
+}
+
+// ShellQuoteArgs quotes each of args with ShellQuote and joins them with
+// spaces, so they reach the command unchanged.
+func ShellQuoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = ShellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
 // GetFirstWord just returns the first space-separated word in a string.
 func GetFirstWord(s string) string {
 	arr := strings.Split(s, " ")
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
 	"testing"
 
@@ -12,6 +13,16 @@ import (
 	asrt "github.com/stretchr/testify/assert"
 )
 
+// TestShellQuoteArgs makes sure quoted arguments survive the shell unchanged.
+func TestShellQuoteArgs(t *testing.T) {
+	assert := asrt.New(t)
+
+	args := []string{"run", "build -- --env=prod", "it's", "$HOME", "a;b", ""}
+	out, err := exec.Command("bash", "-c", "printf '%s\\n' "+util.ShellQuoteArgs(args)).Output()
+	assert.NoError(err)
+	assert.Equal(strings.Join(args, "\n")+"\n", string(out))
+}
+
 // TestRandString ensures that RandString only generates string of the correct value and characters.
 func TestRandString(t *testing.T) {
 	assert := asrt.New(t)
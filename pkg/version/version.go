@@ -47,6 +47,17 @@ var WebImg = "drud/ddev-webserver"
 // WebTag defines the default web image tag for drud dev
 var WebTag = "20190603_drush_launcher" // Note that this can be overridden by make
 
+// BlackfireImg defines the image used for the blackfire agent when profiler: blackfire
+var BlackfireImg = "blackfire/blackfire"
+
+// BlackfireTag defines the tag used for the blackfire agent. The blackfire
+// CLI built into the web image comes from the same agent release.
+var BlackfireTag = "1.27.0"
+
+// BlackfireProbeVersion defines the version of the blackfire PHP probe built
+// into the web image when profiler: blackfire
+var BlackfireProbeVersion = "1.27.0"
+
 // DBImg defines the default db image used for applications.
 var DBImg = "drud/ddev-dbserver"
 
@@ -69,7 +80,7 @@ var BgsyncTag = "v1.8.0" // Note that this can be overridden by make
 var RouterImage = "drud/ddev-router"
 
 // RouterTag defines the tag used for the router.
-var RouterTag = "20190527_dont_blow_away_existing_certs" // Note that this can be overridden by make
+var RouterTag = "20191019_custom_certs" // Note that this can be overridden by make
 
 var SSHAuthImage = "drud/ddev-ssh-agent"
 
@@ -94,6 +105,8 @@ func GetVersionInfo() map[string]string {
 
 	versionInfo["DDEV-Local version"] = DdevVersion
 	versionInfo["web"] = GetWebImage()
+	versionInfo["blackfire"] = BlackfireImg + ":" + BlackfireTag
+	versionInfo["blackfire-probe"] = BlackfireProbeVersion
 	versionInfo["db"] = GetDBImage()
 	versionInfo["dba"] = GetDBAImage()
 	versionInfo["bgsync"] = BgsyncImg + ":" + BgsyncTag